@@ -1,18 +1,49 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/garyloug/tetris/pkg/game"
+	"github.com/garyloug/tetris/pkg/tetris"
 	"github.com/garyloug/tetris/pkg/ui"
 )
 
 func main() {
 	devMode := flag.Bool("dev", false, "Run with a development UI.")
+	generatorName := flag.String("generator", "7bag", "Piece generator: random, 7bag, 14bag or nes.")
+	seed := flag.Int64("seed", 0, "Seed for the game. A random seed is used if not set.")
+	scoringName := flag.String("scoring", "guideline", "Scoring: guideline (T-spins, combos, back-to-back) or nes.")
+	gravityName := flag.String("gravity", "guideline", "Gravity: guideline, nes, classic, or comma separated frames per row for each level.")
+	lockName := flag.String("lock", "move", "Lock delay reset rule: move (15 resets), step or infinite.")
+	lockDelay := flag.Duration("lock-delay", game.DefaultOptions().LockDelay, "How long a tetro can rest on the stack before it locks.")
+	boardHeight := flag.Int("height", game.DefaultOptions().BoardHeight, "Height of the board in blocks, from 4 to 50.")
+	boardWidth := flag.Int("width", game.DefaultOptions().BoardWidth, "Width of the board in blocks, from 4 to 50.")
+	spawnX := flag.Int("spawn-x", game.CentreSpawn, "Column tetros spawn in, counting from 0 at the left. -1 spawns them in the middle of the board.")
+	spawnY := flag.Int("spawn-y", game.DefaultOptions().SpawnY, "Row tetros spawn in, counting from 0 at the top. Negative rows spawn them in the hidden buffer above the board.")
+	queueSize := flag.Int("queue", game.DefaultOptions().QueueSize, "Tetros shown coming up next, from 1 to 7.")
+	modeName := flag.String("mode", "marathon", "Mode: marathon (play for score), sprint (clear lines against the clock), ultra (score as much as possible in a time limit) or dig (clear garbage against the clock).")
+	sprintLines := flag.Int("sprint-lines", game.DefaultOptions().SprintLines, "Lines to clear to finish a sprint.")
+	ultraTime := flag.Duration("ultra-time", game.DefaultOptions().UltraTime, "Time limit of an ultra.")
+	digRows := flag.Int("dig-rows", game.DefaultOptions().DigRows, "Rows of garbage to clear in a dig race.")
+	digMessiness := flag.Float64("dig-messiness", game.DefaultOptions().DigMessiness, "Chance, from 0 to 1, that each row of garbage has its hole in a different column to the row below.")
+	digRise := flag.Duration("dig-rise", 0, "How often a row of garbage rises in a dig race, e.g. 10s. Garbage doesn't rise if not set.")
+	recordPath := flag.String("record", "", "Save a replay of the game to this file.")
+	resume := flag.Bool("resume", false, "Continue the game saved when last quit, with the rules it was started with.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s [flags] replay <file>\n       %s scores\n", os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	var uiType ui.UiType
@@ -22,6 +53,120 @@ func main() {
 		uiType = ui.Console
 	}
 
+	switch flag.Arg(0) {
+	case "":
+	case "replay":
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		playReplay(flag.Arg(1), uiType)
+		return
+	case "scores":
+		printHighScores()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var save *game.Save
+	if *resume {
+		if *recordPath != "" {
+			fmt.Fprintln(os.Stderr, "a resumed game can't be recorded, as the replay would be missing the start of the game")
+			flag.Usage()
+			os.Exit(2)
+		}
+		var err error
+		if save, err = loadGame(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		*generatorName = save.Generator
+		*seed = save.Seed
+	}
+
+	seedSet := *resume
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
+
+	generatorType, err := tetris.ParseGeneratorType(*generatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	options := game.DefaultOptions()
+	options.LockDelay = *lockDelay
+	options.BoardHeight = *boardHeight
+	options.BoardWidth = *boardWidth
+	options.SpawnX = *spawnX
+	options.SpawnY = *spawnY
+	options.QueueSize = *queueSize
+	options.SprintLines = *sprintLines
+	options.UltraTime = *ultraTime
+	options.DigRows = *digRows
+	options.DigMessiness = *digMessiness
+	options.DigRise = *digRise
+	options.Scoring, err = game.ParseScoringType(*scoringName)
+	if err == nil {
+		options.Gravity, err = game.ParseGravity(*gravityName)
+	}
+	if err == nil {
+		options.LockType, err = game.ParseLockType(*lockName)
+	}
+	if err == nil {
+		options.Mode, err = game.ParseModeType(*modeName)
+	}
+	if err == nil {
+		err = options.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	generator, err := tetris.NewGenerator(generatorType)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create generator: %v", err))
+	}
+
+	// a broken high score file shouldn't stop anyone playing, the game just goes without
+	highScores, err := loadHighScores()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load high scores, they won't be kept: %v\n", err)
+	}
+
+	// deferred before the UI cleanup so it prints once the terminal is restored
+	seeds := []int64{*seed}
+	defer func() {
+		fmt.Printf("Seed: %s\n", strings.Trim(fmt.Sprint(seeds), "[]"))
+	}()
+
+	var replay *game.Replay
+	defer func() {
+		if replay != nil {
+			saveReplay(*recordPath, replay)
+		}
+	}()
+
+	// the game is saved when quit before it's over, ready to resume
+	var played *game.Game
+	defer func() {
+		if played != nil {
+			saveGame(played)
+		}
+	}()
+
 	uiInstance, cleanup, err := ui.NewUI(uiType)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create UI: %v", err))
@@ -31,11 +176,225 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	game := game.NewGame(uiInstance)
-	gameOver := game.Start()
+	var g game.Game
+	if save != nil {
+		g, err = game.Resume(uiInstance, game.NewRealClock(), save)
+	} else {
+		g, err = game.NewGame(uiInstance, generator, *seed, game.NewRealClock(), options)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create game: %v", err))
+	}
+	if *recordPath != "" {
+		replay = g.Record()
+	}
+	if highScores != nil {
+		g.UseHighScores(highScores)
+	}
+	g.UseMenus()
+	// restarted games are played from a new seed, every seed is printed so any of the games can be played again
+	g.Subscribe(func(event game.Event) {
+		if event.Type == game.RestartedEvent && event.Seed != seeds[len(seeds)-1] {
+			seeds = append(seeds, event.Seed)
+		}
+	})
+	gameOver := g.Start()
 
 	select {
 	case <-gameOver:
 	case <-sigChan:
+		g.Stop() // wait for the game loop to finish before the UI is cleaned up
+	}
+	played = &g
+}
+
+// configPath is where a file the game keeps between runs is stored, in the user's config directory
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tetris", name), nil
+}
+
+// savePath is where a game in progress is saved when quit
+func savePath() (string, error) {
+	return configPath("save.json")
+}
+
+// loadHighScores loads the high score table, which is empty until the first high score is saved
+func loadHighScores() (*game.HighScores, error) {
+	path, err := configPath("highscores.json")
+	if err != nil {
+		return nil, err
+	}
+	return game.LoadHighScores(path)
+}
+
+// printHighScores prints the high score table of each mode
+func printHighScores() {
+	highScores, err := loadHighScores()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	modes := make([]string, 0, len(highScores.Modes))
+	for mode := range highScores.Modes {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	if len(modes) == 0 {
+		fmt.Println("No high scores yet")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, mode := range modes {
+		fmt.Fprintf(w, "%s\t\tScore\tLevel\tLines\tTime\tDate\tSeed\t\n", mode)
+		for i, score := range highScores.Modes[mode] {
+			fmt.Fprintf(w, "%d.\t%s\t%d\t%d\t%d\t%s\t%s\t%d\t\n",
+				i+1, score.Name, score.Score, score.Level, score.Lines, score.Duration.Round(time.Millisecond), score.Date.Format("2006-01-02"), score.Seed)
+		}
+		fmt.Fprintln(w, "\t\t\t\t\t\t\t\t")
+	}
+	w.Flush()
+}
+
+// saveGame saves a game quit part way through so it can be resumed, or removes the last save once a game is over
+func saveGame(g *game.Game) {
+	path, err := savePath()
+	if err == nil {
+		var saved bool
+		if saved, err = keepGame(path, g); saved {
+			fmt.Println("Game saved, continue it with -resume")
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save game: %v\n", err)
+	}
+}
+
+// savable is the part of a game keepGame needs
+type savable interface {
+	Over() bool
+	Started() bool
+	Save() (*game.Save, error)
+}
+
+// keepGame writes a game quit part way through to the save file, returning whether it was saved. A game that's over
+// removes the save, and one quit before it started leaves the save alone so it can still be resumed.
+func keepGame(path string, g savable) (bool, error) {
+	if g.Over() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+		return false, nil
+	}
+	if !g.Started() {
+		return false, nil
+	}
+
+	save, err := g.Save()
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return false, err
+	}
+	err = game.WriteSave(file, save)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err == nil, err
+}
+
+// loadGame loads the game saved when last quit
+func loadGame() (*game.Save, error) {
+	path, err := savePath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.New("there's no saved game to resume")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return game.ReadSave(file)
+}
+
+// saveReplay writes a recorded game to a file, once the game is over
+func saveReplay(path string, replay *game.Replay) {
+	file, err := os.Create(path)
+	if err == nil {
+		err = game.WriteReplay(file, replay)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save replay: %v\n", err)
+		return
+	}
+	fmt.Printf("Replay saved to %s\n", path)
+}
+
+// playReplay plays a recorded game back, then reports whether it played out as recorded
+func playReplay(path string, uiType ui.UiType) {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	replay, err := game.ReadReplay(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	uiInstance, cleanup, err := ui.NewUI(uiType)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create UI: %v", err))
+	}
+
+	player, err := game.NewPlayer(uiInstance, game.NewRealClock(), replay)
+	if err != nil {
+		cleanup()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	done, err := player.Start()
+	if err != nil {
+		cleanup()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	select {
+	case <-done:
+	case <-sigChan:
+		player.Stop()
+	}
+	cleanup()
+
+	switch {
+	case player.Err() != nil:
+		fmt.Fprintln(os.Stderr, player.Err())
+		os.Exit(1)
+	case player.Finished():
+		fmt.Printf("Replay verified: score %d, %d lines\n", replay.Score, replay.Cleared)
+	default:
+		fmt.Println("Replay stopped before the end")
 	}
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/game"
+)
+
+// testGame is a game at the point it was quit
+type testGame struct {
+	over, started bool
+}
+
+func (g testGame) Over() bool    { return g.over }
+func (g testGame) Started() bool { return g.started }
+
+func (g testGame) Save() (*game.Save, error) {
+	return &game.Save{Version: game.SaveVersion, Options: game.DefaultOptions()}, nil
+}
+
+func TestKeepGame(t *testing.T) {
+	const previous = "previous save"
+
+	tests := []struct {
+		name      string
+		game      testGame
+		wantSaved bool
+		wantFile  bool
+	}{
+		{"quit on the title screen", testGame{}, false, true},
+		{"quit part way through", testGame{started: true}, true, true},
+		{"quit once over", testGame{over: true, started: true}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := os.WriteFile(path, []byte(previous), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			saved, err := keepGame(path, tt.game)
+			if err != nil {
+				t.Fatalf("keepGame() unexpected error: %v", err)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("keepGame() = %v, want %v", saved, tt.wantSaved)
+			}
+
+			data, err := os.ReadFile(path)
+			if exists := err == nil; exists != tt.wantFile {
+				t.Fatalf("save file exists = %v, want %v", exists, tt.wantFile)
+			}
+			if tt.wantFile && (string(data) == previous) == tt.wantSaved {
+				t.Errorf("save file replaced = %v, want %v", string(data) != previous, tt.wantSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock paces the game loop when the game is running in real time.
+// The game itself counts time in frames, so the clock only decides when each frame happens.
+type Clock interface {
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers a tick on its channel every period, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// NewRealClock creates a clock that follows the system time
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// ManualClock only moves forward when Advance is called, so tests can run the game loop without waiting.
+// Unlike a real ticker, no ticks are dropped, Advance blocks until each tick has been received or the ticker is stopped.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+}
+
+func NewManualClock() *ManualClock {
+	return &ManualClock{}
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &manualTicker{
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+		period:  d,
+		next:    c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward, delivering every tick that falls due along the way.
+// It must not be called from more than one goroutine at a time.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	tickers := append([]*manualTicker{}, c.tickers...)
+	c.mu.Unlock()
+
+	for {
+		// find the next tick due, across all running tickers
+		var due *manualTicker
+		for _, t := range tickers {
+			if t.isStopped() || t.next.After(end) {
+				continue
+			}
+			if due == nil || t.next.Before(due.next) {
+				due = t
+			}
+		}
+		if due == nil {
+			break
+		}
+
+		now := due.next
+		due.next = now.Add(due.period)
+		c.setNow(now)
+
+		select {
+		case due.c <- now:
+		case <-due.stopped:
+		}
+	}
+
+	c.setNow(end)
+}
+
+func (c *ManualClock) setNow(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+type manualTicker struct {
+	c       chan time.Time
+	stopped chan struct{}
+	once    sync.Once
+	period  time.Duration
+	next    time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTicker) isStopped() bool {
+	select {
+	case <-t.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *manualTicker) Stop() {
+	t.once.Do(func() { close(t.stopped) })
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// advance runs Advance on the clock, returning the ticks received from the ticker along the way
+func advance(clock *ManualClock, ticker Ticker, d time.Duration) []time.Time {
+	done := make(chan struct{})
+	go func() {
+		clock.Advance(d)
+		close(done)
+	}()
+
+	var ticks []time.Time
+	for {
+		select {
+		case tick := <-ticker.C():
+			ticks = append(ticks, tick)
+		case <-done:
+			return ticks
+		}
+	}
+}
+
+func TestManualClock_Advance(t *testing.T) {
+	clock := NewManualClock()
+	ticker := clock.NewTicker(10 * time.Millisecond)
+
+	// no ticks until a whole period has passed
+	if ticks := advance(clock, ticker, 9*time.Millisecond); len(ticks) != 0 {
+		t.Errorf("Advance(9ms) delivered %d ticks, want 0", len(ticks))
+	}
+
+	// every tick due is delivered, none are dropped
+	ticks := advance(clock, ticker, 91*time.Millisecond)
+	if len(ticks) != 10 {
+		t.Fatalf("Advance(100ms) delivered %d ticks, want 10", len(ticks))
+	}
+	if ticks[1].Sub(ticks[0]) != 10*time.Millisecond {
+		t.Errorf("ticks %v apart, want %v", ticks[1].Sub(ticks[0]), 10*time.Millisecond)
+	}
+}
+
+func TestManualClock_Stop(t *testing.T) {
+	clock := NewManualClock()
+	ticker := clock.NewTicker(10 * time.Millisecond)
+	ticker.Stop()
+
+	// nothing is receiving ticks, so this would block if stopped tickers were still ticking
+	done := make(chan struct{})
+	go func() {
+		clock.Advance(time.Second)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Advance() blocked on a stopped ticker")
+	}
+
+	// stopping again is safe
+	ticker.Stop()
+}
+
+func TestRealClock(t *testing.T) {
+	ticker := NewRealClock().NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+	case <-time.After(time.Second):
+		t.Fatal("real ticker did not tick")
+	}
+}
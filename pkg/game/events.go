@@ -0,0 +1,87 @@
+package game
+
+import "github.com/garyloug/tetris/pkg/tetris"
+
+const (
+	SpawnedEvent EventType = iota
+	MovedEvent
+	RotatedEvent
+	LockedEvent
+	LinesClearedEvent
+	LevelUpEvent
+	ScoreChangedEvent
+	HeldEvent
+	PausedEvent
+	ResumedEvent
+	GameOverEvent
+	RestartedEvent
+)
+
+// EventType is what happened in the game
+type EventType int
+
+var eventNames = map[EventType]string{
+	SpawnedEvent:      "spawned",
+	MovedEvent:        "moved",
+	RotatedEvent:      "rotated",
+	LockedEvent:       "locked",
+	LinesClearedEvent: "lines cleared",
+	LevelUpEvent:      "level up",
+	ScoreChangedEvent: "score changed",
+	HeldEvent:         "held",
+	PausedEvent:       "paused",
+	ResumedEvent:      "resumed",
+	GameOverEvent:     "game over",
+	RestartedEvent:    "restarted",
+}
+
+func (e EventType) String() string {
+	if name, ok := eventNames[e]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event tells observers about something that happened in the game. Only the fields for its type are set.
+type Event struct {
+	Type   EventType
+	Frame  int            // frame the event happened on
+	Blocks []tetris.Block // the tetro's blocks, for spawned, moved, rotated, locked and held
+	Rows   []int          // rows cleared, from the top down, for lines cleared
+	Level  int            // new level, for level up
+	Score  int            // new score, for score changed and game over
+	Points int            // points just scored, for score changed
+	Scored ScoreEvent     // what the tetro scored, for locked
+	Seed   int64          // new game's seed, for restarted
+}
+
+// Observer is told about each event as it happens, on the game loop's goroutine, so it must return quickly and not
+// call back into the game
+type Observer func(event Event)
+
+// Subscribe adds an observer for the game's events
+func (g *Game) Subscribe(observer Observer) {
+	g.observers = append(g.observers, observer)
+}
+
+// emit tells every observer about an event, stamped with the current frame
+func (g *Game) emit(event Event) {
+	event.Frame = g.frames
+	for _, observer := range g.observers {
+		observer(event)
+	}
+}
+
+// emitTetro tells every observer about an event for the active tetro
+func (g *Game) emitTetro(eventType EventType) {
+	g.emit(Event{Type: eventType, Blocks: g.activeTetro.Blocks()})
+}
+
+// addScore adds points to the score, telling observers about the change
+func (g *Game) addScore(points int) {
+	if points == 0 {
+		return
+	}
+	g.score += points
+	g.emit(Event{Type: ScoreChangedEvent, Score: g.score, Points: points})
+}
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// recordEvents subscribes to the game's events, returning the events seen so far each time it's called
+func recordEvents(game *Game) func() []Event {
+	var events []Event
+	game.Subscribe(func(event Event) {
+		events = append(events, event)
+	})
+	return func() []Event {
+		seen := events
+		events = nil
+		return seen
+	}
+}
+
+func eventTypes(events []Event) []EventType {
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestGame_Events(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []ui.KeyPress
+		expected []EventType
+	}{
+		{"move", []ui.KeyPress{ui.KeyLeft, ui.KeyRight}, []EventType{MovedEvent, MovedEvent}},
+		{"rotate", []ui.KeyPress{ui.KeyUp}, []EventType{RotatedEvent}},
+		{"soft drop", []ui.KeyPress{ui.KeyDown}, []EventType{ScoreChangedEvent, MovedEvent}},
+		{
+			"hard drop",
+			[]ui.KeyPress{ui.KeyHardDrop},
+			[]EventType{MovedEvent, ScoreChangedEvent, LockedEvent, SpawnedEvent},
+		},
+		{"hold", []ui.KeyPress{ui.KeyHold, ui.KeyHold}, []EventType{HeldEvent, SpawnedEvent}},
+		{"pause", []ui.KeyPress{ui.KeyPause, ui.KeyLeft, ui.KeyPause}, []EventType{PausedEvent, ResumedEvent}},
+		{"ghost", []ui.KeyPress{ui.KeyGhost}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			events := recordEvents(game)
+
+			for _, key := range tt.keys {
+				game.Press(key)
+			}
+
+			if types := eventTypes(events()); !reflect.DeepEqual(types, tt.expected) {
+				t.Errorf("events = %v, want %v", types, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGame_EventsDescribeTheTetro(t *testing.T) {
+	game := newTestGame(t)
+	events := recordEvents(game)
+
+	game.Step(3)
+	game.Press(ui.KeyLeft)
+	moved := events()
+	if len(moved) != 1 || moved[0].Type != MovedEvent {
+		t.Fatalf("events = %v, want a single move", eventTypes(moved))
+	}
+	if !reflect.DeepEqual(moved[0].Blocks, game.activeTetro.Blocks()) {
+		t.Errorf("moved event blocks = %v, want %v", moved[0].Blocks, game.activeTetro.Blocks())
+	}
+	if moved[0].Frame != 3 {
+		t.Errorf("moved event frame = %d, want 3", moved[0].Frame)
+	}
+
+	// the event keeps the blocks where they were, even once the tetro moves on
+	game.Press(ui.KeyLeft)
+	if reflect.DeepEqual(moved[0].Blocks, game.activeTetro.Blocks()) {
+		t.Error("moved event blocks changed when the tetro moved again")
+	}
+}
+
+func TestGame_EventsForClearedLines(t *testing.T) {
+	game := newTestGame(t)
+	bottom := game.boardHeight - 1
+
+	// fill the bottom row, apart from where the active tetro will land
+	landing := map[int]bool{}
+	for _, block := range game.activeTetro.Ghost(game.board) {
+		x, y := block.Coordinates()
+		if y == bottom {
+			landing[x] = true
+		}
+	}
+	var blocks []tetris.Block
+	for x := 0; x < game.boardWidth; x++ {
+		if !landing[x] {
+			blocks = append(blocks, tetris.NewBlock(x, bottom, "fill"))
+		}
+	}
+	game.board.Place(blocks)
+	game.cleared = 9 // one more line to level up
+
+	events := recordEvents(game)
+	game.hardDrop()
+
+	var cleared, levelUp, locked *Event
+	seen := events()
+	for i := range seen {
+		switch seen[i].Type {
+		case LinesClearedEvent:
+			cleared = &seen[i]
+		case LevelUpEvent:
+			levelUp = &seen[i]
+		case LockedEvent:
+			locked = &seen[i]
+		}
+	}
+
+	if locked == nil || locked.Scored.Action != "Single" {
+		t.Errorf("locked event = %+v, want a single scored", locked)
+	}
+	if cleared == nil || !reflect.DeepEqual(cleared.Rows, []int{bottom}) {
+		t.Errorf("lines cleared event = %+v, want row %d", cleared, bottom)
+	}
+	if levelUp == nil || levelUp.Level != 1 {
+		t.Errorf("level up event = %+v, want level 1", levelUp)
+	}
+}
+
+func TestGame_GameOverEvent(t *testing.T) {
+	game := newTestGame(t)
+	events := recordEvents(game)
+
+	for i := 0; i < 100 && !game.Over(); i++ {
+		game.hardDrop()
+	}
+	if !game.Over() {
+		t.Fatal("game should be over after stacking tetros to the top")
+	}
+
+	seen := events()
+	last := seen[len(seen)-1]
+	if last.Type != GameOverEvent {
+		t.Fatalf("last event = %v, want %v", last.Type, GameOverEvent)
+	}
+	if last.Score != game.Score() {
+		t.Errorf("game over event score = %d, want %d", last.Score, game.Score())
+	}
+}
+
+func TestEventType_String(t *testing.T) {
+	if name := LinesClearedEvent.String(); name != "lines cleared" {
+		t.Errorf("String() = %q, want %q", name, "lines cleared")
+	}
+	if name := EventType(99).String(); name != "unknown" {
+		t.Errorf("String() = %q, want %q", name, "unknown")
+	}
+}
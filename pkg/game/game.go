@@ -1,6 +1,7 @@
 package game
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/garyloug/tetris/pkg/tetris"
@@ -8,54 +9,116 @@ import (
 )
 
 const (
-	boardHeight    = 20
-	boardWidth     = 10
-	tetroSpawnX    = 5
-	tetroSpawnY    = 0
-	tetroQueueSize = 5
-	userInputClock = 50 * time.Millisecond
-	pauseForEffect = 100 * time.Millisecond
-	moveDelay      = 500 * time.Millisecond
+	frameRate         = 60 // frames per second
+	frameDuration     = time.Second / frameRate
+	pauseForEffect    = 100 * time.Millisecond
+	actionDisplayTime = 2 * time.Second // how long a scoring action is shown
+	softDropPoints    = 1               // per row dropped
+	hardDropPoints    = 2               // per row dropped
+	bufferRows        = 20              // hidden rows above the board, where the stack can build up out of sight
 )
 
 type Game struct {
-	ui               ui.UI
-	boardHeight      int
-	boardWidth       int
-	score            int
-	level            int
-	cleared          int
-	pause            bool
-	over             bool
-	activeTetro      tetris.Tetro
-	tetroQueue       []tetris.Tetro
-	stationaryBlocks []tetris.Block
-	speed            time.Duration
-	moveTimer        time.Time
-	moveDelay        time.Duration
-	done             chan struct{}
-}
-
-func NewGame(ui ui.UI) Game {
-	return Game{
-		ui:          ui,
-		boardHeight: boardHeight,
-		boardWidth:  boardWidth,
-		tetroQueue:  make([]tetris.Tetro, tetroQueueSize),
-		moveDelay:   moveDelay,
-		level:       0,
-		score:       0,
-		cleared:     0,
-		done:        make(chan struct{}),
+	ui          ui.UI
+	boardHeight int
+	boardWidth  int
+	score       int
+	level       int
+	cleared     int
+	pause       bool
+	over        bool
+	showGhost   bool
+	activeTetro tetris.Tetro
+	heldTetro   tetris.Tetro
+	holdUsed    bool
+	tetroQueue  []tetris.Tetro
+	factory     *tetris.Factory
+	generator   tetris.Generator
+	seed        int64
+	rng         *rand.Rand
+	dealt       int // tetros dealt by the generator so far
+	board       *tetris.Board
+	clock       Clock
+	frames      int // frames played so far, the game's own measure of time
+	fallen      int // how far the active tetro has fallen towards the next row, in 1/65536ths of a row
+	options     Options
+	scoring     Scoring
+	mode        mode
+	pieces      int      // tetros locked so far
+	keys        int      // key presses that moved, rotated, dropped or held the active tetro
+	completed   bool     // the mode's goal was reached, rather than the stack reaching the top
+	results     []string // the mode's results, shown once the game is over
+	garbage     int      // rows of garbage left on the board. Rows are only ever removed, so these are always the bottom rows.
+	rotated     bool     // the last thing the active tetro did was rotate, needed for T-spins
+	action      string   // label for the last scoring action, shown for a short time
+	actionTime  int      // frames left to show the action
+	lockTimer   int      // frames the active tetro has rested on the stack since the lock delay last started
+	lockResets  int      // times the lock delay has been reset by moving or rotating
+	lowestRow   int      // lowest row the active tetro has reached
+	clearTime   int      // frames left before cleared rows are removed
+	clearedRows []int    // rows cleared and waiting to be removed
+	observers   []Observer
+	replay      *Replay     // recording of the game, nil if it isn't being recorded
+	muted       bool        // the UI isn't updated while a replay is fast forwarded
+	highScores  *HighScores // table the score goes in once the game is over, nil if scores aren't kept
+	endedAt     time.Time
+	naming      bool   // the player is entering their name for the high score table
+	name        []byte // name being entered, one letter at a time
+	nameCursor  int
+	newScore    int      // row of the game's score in the high score table, -1 if it's not in the table
+	menus       bool     // the game is played through the menus, see UseMenus
+	menu        *menu    // menu being shown, nil while playing
+	settings    settings // rules chosen in the menus for the next game
+	stopped     bool
+	quit        chan struct{}
+	done        chan struct{}
+}
+
+// NewGame creates a game that deals its tetros from the given generator, with the first tetros already dealt.
+// All randomness in the game comes from the seed, so games with the same seed and generator play out the same.
+// The clock only paces the game loop once the game is started, games can also be stepped a frame at a time with Step.
+func NewGame(ui ui.UI, generator tetris.Generator, seed int64, clock Clock, options Options) (Game, error) {
+	g, err := newGame(ui, generator, seed, clock, options)
+	if err != nil {
+		return Game{}, err
 	}
+	g.dealFirst()
+
+	return g, nil
 }
 
-func (g *Game) Start() <-chan struct{} {
-	o, i, s, z, l, j, t := g.ui.GetBlockStyles()
+// dealFirst sets up the board for the game's mode, then deals the first tetro and fills the queue
+func (g *Game) dealFirst() {
+	g.mode.setup(g)
+	g.activeTetro = g.deal()
+	for i := range g.tetroQueue {
+		g.tetroQueue[i] = g.deal()
+	}
+	g.spawned()
+}
+
+// newGame creates a game with an empty board, before any tetros are dealt
+func newGame(ui ui.UI, generator tetris.Generator, seed int64, clock Clock, options Options) (Game, error) {
+	if err := options.Validate(); err != nil {
+		return Game{}, err
+	}
+
+	scoring, err := NewScoring(options.Scoring)
+	if err != nil {
+		return Game{}, err
+	}
+
+	mode, err := newMode(options, seed)
+	if err != nil {
+		return Game{}, err
+	}
+
+	o, i, s, z, l, j, t := ui.GetBlockStyles()
 
-	tetrisConfig := tetris.Config{
-		SpawnX: tetroSpawnX,
-		SpawnY: tetroSpawnY,
+	// each game has its own tetro factory, so games don't share spawn positions or styles
+	factory := tetris.NewFactory(tetris.Config{
+		SpawnX: options.spawnX(),
+		SpawnY: options.SpawnY,
 		StyleO: o,
 		StyleI: i,
 		StyleS: s,
@@ -63,248 +126,625 @@ func (g *Game) Start() <-chan struct{} {
 		StyleL: l,
 		StyleJ: j,
 		StyleT: t,
-	}
-
-	tetris.Init(tetrisConfig)
 
-	g.activeTetro = tetris.NewRandomTetro()
-	g.stationaryBlocks = []tetris.Block{}
+		StyleGarbage: ui.GetGarbageStyle(),
+	})
 
-	for i := 0; i < tetroQueueSize; i++ {
-		tetro := tetris.NewRandomTetro()
-		g.tetroQueue[i] = tetro
+	g := Game{
+		ui:          ui,
+		boardHeight: options.BoardHeight,
+		boardWidth:  options.BoardWidth,
+		board:       tetris.NewBufferedBoard(options.BoardHeight, options.BoardWidth, bufferRows),
+		tetroQueue:  make([]tetris.Tetro, options.QueueSize),
+		factory:     factory,
+		generator:   generator,
+		showGhost:   true,
+		seed:        seed,
+		rng:         rand.New(rand.NewSource(seed)),
+		clock:       clock,
+		options:     options,
+		scoring:     scoring,
+		mode:        mode,
+		level:       0,
+		score:       0,
+		cleared:     0,
+		newScore:    -1,
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
 	}
 
+	return g, nil
+}
+
+// Start runs the game loop in real time, a frame every tick of the game's clock. The game loop owns the game from then
+// on, so the game is set up, stepped and saved before it's started, never after.
+func (g *Game) Start() <-chan struct{} {
 	if err := g.ui.Init(g.boardHeight, g.boardWidth); err != nil {
 		// TODO implement log to file
 		panic(err)
 	}
 	g.ui.Start()
-	g.resetMoveTimer()
 	g.updateUI()
 
-	go g.userEvents()
-	go g.run()
+	// the ticker is created before the game loop starts, so no ticks are missed
+	go g.run(g.clock.NewTicker(frameDuration))
 
 	return g.done
 }
 
-// run is the main game loop
-func (g *Game) run() {
-	for !g.over {
+// Stop ends the game from outside the game loop, e.g. on a signal, and waits for the game loop to finish
+func (g *Game) Stop() {
+	select {
+	case g.quit <- struct{}{}:
+	case <-g.done:
+	}
+	<-g.done
+}
 
-		// wait while game paused
-		for g.pause {
-			g.updateUI()
-			time.Sleep(100 * time.Millisecond)
-		}
+// Step advances the game by a number of frames straight away, without waiting on the clock
+func (g *Game) Step(frames int) {
+	for i := 0; i < frames; i++ {
+		g.frame()
+	}
+}
 
-		// move active tetro down in time with the game clock, speeds up with level
-		time.Sleep(500*time.Millisecond - g.speed)
-		g.moveTetroDown()
+// Press handles a key press straight away, for use with Step
+func (g *Game) Press(keypress ui.KeyPress) {
+	g.processKeyPress(keypress)
+}
 
-		// check for game over
-		if g.reachedTop() {
-			g.over = true
-			break
-		}
+func (g *Game) Frames() int {
+	return g.frames
+}
 
-		// check for cleared lines and update score
-		numCleared := g.clearCompletedLines()
-		if numCleared > 0 {
-			switch numCleared {
-			case 1:
-				g.score += 40 * (g.level + 1)
-			case 2:
-				g.score += 100 * (g.level + 1)
-			case 3:
-				g.score += 300 * (g.level + 1)
-			case 4:
-				g.score += 1200 * (g.level + 1)
-			}
-		}
-		g.cleared += numCleared
+// Started checks whether any of the game has been played, a new game waiting on the title screen hasn't been
+func (g *Game) Started() bool {
+	return g.frames > 0
+}
 
-		// update level based on cleared lines, cap at 20
-		g.level = g.cleared / 10
-		if g.level > 20 {
-			g.level = 20
-		}
+func (g *Game) Seed() int64 {
+	return g.seed
+}
 
-		// update speed based on level
-		g.speed = time.Duration(g.level*20) * time.Millisecond
+func (g *Game) Score() int {
+	return g.score
+}
 
-		g.updateUI()
-	}
+func (g *Game) Level() int {
+	return g.level
+}
 
-	// final update with result
-	g.updateUI()
+func (g *Game) Cleared() int {
+	return g.cleared
 }
 
-// userEvents is a secondary loop to handle user inputs
-func (g *Game) userEvents() {
+func (g *Game) Over() bool {
+	return g.over
+}
+
+// run is the main game loop. It is the only goroutine that changes the game state,
+// user inputs and frames are handled one at a time, in the order they arrive.
+func (g *Game) run(ticker Ticker) {
+	defer ticker.Stop()
+
 	for {
 		select {
-		case event := <-g.ui.KeyPress():
-			g.processKeyPress(event)
-		case <-g.done:
+		case keypress, ok := <-g.ui.KeyPress():
+			if !ok {
+				g.stop() // UI has gone away
+				return
+			}
+			g.processKeyPress(keypress)
+			if g.stopped {
+				return
+			}
+		case <-ticker.C():
+			g.frame()
+		case <-g.quit:
+			g.stop()
 			return
 		}
 	}
 }
 
+// frame advances the game by one frame. Nothing moves while paused, and gravity waits while cleared rows are removed.
+// The game ends on the frame a timed mode runs out of time, or garbage rising pushes the stack off the top.
+func (g *Game) frame() {
+	if g.over || g.pause || g.menu != nil {
+		return
+	}
+	g.frames++
+	g.mode.tick(g)
+	if g.over {
+		return
+	}
+	if g.mode.finished(g) {
+		g.completed = true
+		g.end()
+		return
+	}
+
+	if g.actionTime > 0 {
+		g.actionTime--
+		if g.actionTime == 0 {
+			g.action = ""
+		}
+	}
+
+	if g.clearTime > 0 {
+		g.clearTime--
+		if g.clearTime == 0 {
+			g.removeClearedRows()
+		}
+	} else {
+		g.applyGravity()
+		g.checkLock()
+	}
+
+	// the game's timer moves on every frame, so the UI is updated every frame rather than only when something moves
+	g.updateUI()
+}
+
+// applyGravity moves the active tetro down by however many whole rows it has fallen this frame, which can be many at high levels, and all the way to the stack at 20G
+func (g *Game) applyGravity() {
+	if !g.activeTetro.CanMoveDown(g.board) {
+		g.fallen = 0
+		return
+	}
+
+	speed := g.options.Gravity.Speed(g.level)
+	if speed >= maxGravity {
+		g.fallen = 0
+		g.dropToStack()
+		return
+	}
+
+	g.fallen += speed
+	if rows := g.fallen / rowFraction; rows > 0 {
+		g.fallen %= rowFraction
+		g.fall(rows)
+	}
+}
+
+// instantGravity drops a newly spawned tetro straight onto the stack at 20G, so it never appears at the spawn position.
+// While cleared rows wait to be removed the drop waits too, as the stack is about to move.
+func (g *Game) instantGravity() {
+	if g.clearTime == 0 && g.options.Gravity.Speed(g.level) >= maxGravity {
+		g.dropToStack()
+	}
+}
+
+// dropToStack moves the active tetro down until it lands on the stack, from anywhere in the buffer or on the board
+func (g *Game) dropToStack() {
+	g.fall(bufferRows + g.boardHeight)
+}
+
+// fall moves the active tetro down a number of rows, stopping early if it lands on the stack
+func (g *Game) fall(rows int) {
+	fell := false
+	for i := 0; i < rows && g.activeTetro.CanMoveDown(g.board); i++ {
+		g.activeTetro.MoveDown()
+		g.movedDown()
+		g.rotated = false
+		fell = true
+	}
+	if fell {
+		g.emitTetro(MovedEvent)
+	}
+}
+
+// lockTetro locks the active tetro in place, checks for game over and cleared lines, then deals the next tetro
+// unless the mode's goal has been reached
+func (g *Game) lockTetro() {
+	g.pieces++
+	spin := tetris.NoSpin
+	if g.rotated {
+		spin = g.activeTetro.Spin(g.board)
+	}
+	blocks := g.activeTetro.Blocks()
+	g.board.Place(blocks)
+
+	// check for game over
+	if lockedOut(blocks) {
+		g.emit(Event{Type: LockedEvent, Blocks: blocks})
+		g.end()
+		return
+	}
+
+	// check for cleared lines and update score
+	numCleared := g.clearCompletedLines()
+	scored := g.scoring.Lock(Clear{
+		Lines:        numCleared,
+		Spin:         spin,
+		PerfectClear: numCleared > 0 && g.board.Empty(),
+	}, g.level)
+	g.emit(Event{Type: LockedEvent, Blocks: blocks, Scored: scored})
+	if numCleared > 0 {
+		g.emit(Event{Type: LinesClearedEvent, Rows: append([]int(nil), g.clearedRows...)})
+	}
+	g.addScore(scored.Points)
+	if scored.Action != "" {
+		g.action = scored.Label()
+		g.actionTime = framesIn(actionDisplayTime)
+	}
+	g.cleared += numCleared
+
+	// update level based on cleared lines, gravity speeds up with level
+	if level := g.cleared / 10; level != g.level {
+		g.level = level
+		g.emit(Event{Type: LevelUpEvent, Level: g.level})
+	}
+
+	if g.mode.finished(g) {
+		g.completed = true
+		g.end()
+		return
+	}
+
+	g.nextTetro()
+	g.updateUI()
+}
+
+// end finishes the game, either because the stack reached the top or because the mode's goal was reached
+func (g *Game) end() {
+	g.over = true
+	g.emit(Event{Type: GameOverEvent, Score: g.score})
+	g.recordResult()
+	g.results = g.mode.results(g) // before the game goes in the high score table, to compare with the best so far
+	g.showHighScores()
+	g.updateUI() // final update with result
+}
+
+// framesIn converts a duration to a whole number of frames
+func framesIn(d time.Duration) int {
+	return int(d / frameDuration)
+}
+
+// stop stops the UI and signals the game is done
+func (g *Game) stop() {
+	g.stopped = true
+	g.recordResult()
+	g.ui.Stop()
+	close(g.done)
+}
+
 func (g *Game) processKeyPress(keypress ui.KeyPress) {
+	// menu keys aren't recorded, only what they do to the game
+	if g.menu != nil {
+		g.menuKey(keypress)
+		return
+	}
+	if g.menus && g.openMenu(keypress) {
+		return
+	}
+
+	// a restart isn't recorded, the replay is only of the game played up to it
+	if keypress == ui.KeyRestart {
+		if (g.over && !g.naming) || g.pause {
+			g.restart()
+		}
+		return
+	}
+
+	g.recordInput(keypress)
+	if pieceKeys[keypress] && g.playing() {
+		g.keys++
+	}
+
+	if g.naming && keypress != ui.KeyStop {
+		g.enterName(keypress)
+		return
+	}
+
 	switch keypress {
 	case ui.KeyUp:
-		if !g.over && !g.pause {
-			g.rotateTetro()
+		if g.playing() {
+			g.rotateTetro(g.activeTetro.Rotate)
+		}
+	case ui.KeyRotateLeft:
+		if g.playing() {
+			g.rotateTetro(g.activeTetro.RotateLeft)
+		}
+	case ui.KeyRotate180:
+		if g.playing() {
+			g.rotateTetro(g.activeTetro.Rotate180)
 		}
 	case ui.KeyDown:
-		if !g.over && !g.pause {
-			g.moveTetroDown()
+		if g.playing() {
+			g.softDrop()
+		}
+	case ui.KeyHardDrop:
+		if g.playing() {
+			g.hardDrop()
 		}
 	case ui.KeyLeft:
-		if !g.over && !g.pause {
+		if g.playing() {
 			g.moveTetroLeft()
 		}
 	case ui.KeyRight:
-		if !g.over && !g.pause {
+		if g.playing() {
 			g.moveTetroRight()
 		}
+	case ui.KeyHold:
+		if g.playing() {
+			g.holdTetro()
+		}
+	case ui.KeyGhost:
+		g.showGhost = !g.showGhost
+		g.updateUI()
 	case ui.KeyPause:
 		if !g.over {
 			g.pause = !g.pause
+			if g.pause {
+				g.emit(Event{Type: PausedEvent})
+			} else {
+				g.emit(Event{Type: ResumedEvent})
+			}
+			if g.menus && g.pause {
+				g.menu = &menu{screen: pauseMenu}
+			}
+			g.updateUI()
 		}
 	case ui.KeyStop:
-		g.ui.Stop()
-		close(g.done)
+		g.stop()
+	}
+}
+
+// pieceKeys are the keys that move, rotate, drop or hold the active tetro, counted for keys per piece
+var pieceKeys = map[ui.KeyPress]bool{
+	ui.KeyUp:         true,
+	ui.KeyRotateLeft: true,
+	ui.KeyRotate180:  true,
+	ui.KeyDown:       true,
+	ui.KeyHardDrop:   true,
+	ui.KeyLeft:       true,
+	ui.KeyRight:      true,
+	ui.KeyHold:       true,
+}
+
+// restart replaces the game with a fresh one in the same game loop and UI, with the same rules, high score table and
+// observers. The new game's seed is the next random number, so a run of games started from a seed always plays out the same.
+func (g *Game) restart() {
+	g.generator.Reset()
+	g.replace(g.generator, g.rng.Int63(), g.options)
+}
+
+// replace replaces the game with a fresh one, keeping the game loop, UI, high score table, menus and observers.
+// Any recording ends, a replay is only ever of one game.
+func (g *Game) replace(generator tetris.Generator, seed int64, options Options) {
+	g.recordResult()
+
+	fresh, err := newGame(g.ui, generator, seed, g.clock, options)
+	if err != nil {
+		panic(err) // options are checked when the game is created and by the options menu
 	}
+	fresh.observers = g.observers
+	fresh.highScores = g.highScores
+	fresh.menus = g.menus
+	fresh.settings = g.settings
+	fresh.muted = g.muted
+	fresh.quit = g.quit
+	fresh.done = g.done
+
+	*g = fresh
+	g.emit(Event{Type: RestartedEvent, Seed: g.seed})
+	g.dealFirst()
+	g.updateUI()
 }
 
+// playing checks the active tetro can be moved by the player, i.e. the game isn't over or paused, and no rows are being removed
+func (g *Game) playing() bool {
+	return !g.over && !g.pause && g.clearTime == 0
+}
+
+// moveTetroDown moves the active tetro down a row if it can, otherwise it's left to the lock delay to lock it in place
 func (g *Game) moveTetroDown() {
-	if g.activeTetro.CanMoveDown(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
+	if g.activeTetro.CanMoveDown(g.board) {
+		g.fall(1)
+		g.updateUI()
+	}
+}
+
+// softDrop moves the active tetro down a row at the player's request, scoring if it moves
+func (g *Game) softDrop() {
+	if g.activeTetro.CanMoveDown(g.board) {
+		g.addScore(softDropPoints)
+	}
+	g.moveTetroDown()
+}
+
+// hardDrop drops the active tetro as far as it can go and locks it in place immediately
+func (g *Game) hardDrop() {
+	rows := 0
+	for g.activeTetro.CanMoveDown(g.board) {
 		g.activeTetro.MoveDown()
-		g.resetMoveTimer()
-	} else {
-		// tetro can't move down, start/check timer
-		if g.moveTimer.IsZero() {
-			g.moveTimer = time.Now() // start timer
-		} else if time.Since(g.moveTimer) >= g.moveDelay {
-			g.nextTetro()      // timer expired, get next tetro
-			g.resetMoveTimer() // reset for next tetro
-		}
+		g.rotated = false
+		rows++
 	}
-	g.updateUI()
+	if rows > 0 {
+		g.emitTetro(MovedEvent)
+		g.addScore(rows * hardDropPoints)
+	}
+	g.lockTetro()
 }
 
 func (g *Game) moveTetroLeft() {
-	if g.activeTetro.CanMoveLeft(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
+	if g.activeTetro.CanMoveLeft(g.board) {
 		g.activeTetro.MoveLeft()
+		g.rotated = false
+		g.emitTetro(MovedEvent)
+		g.moved()
 		g.updateUI()
-
-		if !g.activeTetro.CanMoveDown(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
-			g.resetMoveTimer()
-		}
 	}
 }
 
 func (g *Game) moveTetroRight() {
-	if g.activeTetro.CanMoveRight(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
+	if g.activeTetro.CanMoveRight(g.board) {
 		g.activeTetro.MoveRight()
+		g.rotated = false
+		g.emitTetro(MovedEvent)
+		g.moved()
 		g.updateUI()
-
-		if !g.activeTetro.CanMoveDown(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
-			g.resetMoveTimer()
-		}
 	}
 }
 
-func (g *Game) rotateTetro() {
-	if g.activeTetro.CanRotate(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
-		g.activeTetro.Rotate()
+// rotateTetro applies one of the active tetro's rotate methods, i.e. clockwise, counter-clockwise or 180 degrees
+func (g *Game) rotateTetro(rotate func(board *tetris.Board) bool) {
+	if rotate(g.board) {
+		g.rotated = true
+		g.emitTetro(RotatedEvent)
+		g.moved()
 		g.updateUI()
-
-		if !g.activeTetro.CanMoveDown(g.boardHeight, g.boardWidth, g.stationaryBlocks) {
-			g.resetMoveTimer()
-		}
 	}
 }
 
+// nextTetro takes the next tetro from the queue once the active tetro has locked
 func (g *Game) nextTetro() {
-	// append active tetro blocks to stationary blocks
-	g.stationaryBlocks = append(g.stationaryBlocks, g.activeTetro.Blocks()...)
+	g.activeTetro = g.takeFromQueue()
+	g.holdUsed = false
+	g.spawned()
+}
+
+// spawned starts a new active tetro falling from the spawn position.
+// The game is over if the tetro spawns overlapping the stack, known as a block out.
+func (g *Game) spawned() {
+	g.fallen = 0
+	g.rotated = false
+	g.resetLock()
+	g.emitTetro(SpawnedEvent)
+	// while cleared rows wait to be removed the stack is about to move, so the block out waits for it to settle
+	if g.clearTime == 0 {
+		g.blockOut()
+	}
+}
 
-	// take the next tetro from the queue
-	g.activeTetro = g.tetroQueue[0]
+// blockOut ends the game if the active tetro overlaps the stack, otherwise it's dropped to the stack at 20G
+func (g *Game) blockOut() {
+	if !g.activeTetro.Fits(g.board) {
+		g.end()
+		return
+	}
+	g.instantGravity()
+}
+
+// holdTetro swaps the active tetro with the held one, or with the next in the queue if nothing is held yet.
+// The tetro coming out of hold starts again from the spawn position and rotation.
+// Hold can only be used once per tetro, until the active tetro locks in place.
+func (g *Game) holdTetro() {
+	if g.holdUsed {
+		return
+	}
+
+	g.emitTetro(HeldEvent)
+	held := g.heldTetro
+	g.heldTetro = g.activeTetro.Spawn()
+	if held == nil {
+		g.activeTetro = g.takeFromQueue()
+	} else {
+		g.activeTetro = held
+	}
+	g.holdUsed = true
+
+	g.spawned()
+	g.updateUI()
+}
+
+// takeFromQueue removes the next tetro from the front of the queue and refills the end from the generator
+func (g *Game) takeFromQueue() tetris.Tetro {
+	next := g.tetroQueue[0]
 	g.tetroQueue = g.tetroQueue[1:]
 
-	// add a new random tetro to the end of the queue
-	newTetro := tetris.NewRandomTetro()
-	g.tetroQueue = append(g.tetroQueue, newTetro)
+	g.tetroQueue = append(g.tetroQueue, g.deal())
+
+	return next
 }
 
-func (g *Game) reachedTop() bool {
-	for _, block := range g.stationaryBlocks {
-		_, y := block.Coordinates()
-		if y < 1 {
-			return true
+// deal takes a new tetro from the generator. Tetros dealt are counted, as a saved game brings the generator
+// and the random numbers it uses back to the same state by dealing the same number again.
+func (g *Game) deal() tetris.Tetro {
+	g.dealt++
+	return g.generator.Next(g.factory, g.rng)
+}
+
+// lockedOut checks if a tetro locked entirely in the buffer above the board, out of sight, known as a lock out.
+// Tetros can lock partly in the buffer and the game goes on, as long as some of the tetro can be seen.
+func lockedOut(blocks []tetris.Block) bool {
+	for _, block := range blocks {
+		if _, y := block.Coordinates(); y >= 0 {
+			return false
 		}
 	}
-	return false
+	return true
 }
 
+// clearCompletedLines empties any completed lines straight away, pausing for effect before the lines above drop down
 func (g *Game) clearCompletedLines() (completedLines int) {
-	for y := g.boardHeight - 1; y >= 0; y-- {
-		lineComplete := true
-		for x := 0; x < g.boardWidth; x++ {
-			found := false
-			for _, block := range g.stationaryBlocks {
-				blockX, blockY := block.Coordinates()
-				if blockX == x && blockY == y {
-					found = true
-					break
-				}
-			}
-			if !found {
-				lineComplete = false
-				break
-			}
-		}
-		if lineComplete {
+	garbageCleared := 0
+	for y := -bufferRows; y < g.boardHeight; y++ {
+		if g.board.RowFull(y) {
 			completedLines++
-			g.removeLine(y)
-			y++ // check the same line again after removing
+			if y >= g.boardHeight-g.garbage {
+				garbageCleared++
+			}
+			g.board.ClearRow(y)
+			g.clearedRows = append(g.clearedRows, y)
 		}
 	}
+	g.garbage -= garbageCleared
+	if completedLines > 0 {
+		g.clearTime = framesIn(pauseForEffect)
+	}
 	return completedLines
 }
 
-func (g *Game) removeLine(y int) {
-	i := 0
-	for _, block := range g.stationaryBlocks {
-		_, blockY := block.Coordinates()
-		if blockY != y {
-			g.stationaryBlocks[i] = block
-			i++
+// addGarbage pushes the stack up a row and fills the bottom row with garbage, apart from a hole in one column.
+// The active tetro is pushed up with the stack if it's in the way. It returns false if the stack was pushed off the top.
+func (g *Game) addGarbage(hole int) bool {
+	fits := g.board.PushUp()
+	var blocks []tetris.Block
+	for x := 0; x < g.boardWidth; x++ {
+		if x != hole {
+			blocks = append(blocks, tetris.NewBlock(x, g.boardHeight-1, g.factory.GarbageStyle()))
 		}
 	}
-	g.stationaryBlocks = g.stationaryBlocks[:i]
+	g.board.Place(blocks)
+	g.garbage++
+
+	// there's no active tetro yet while the board is set up
+	if g.activeTetro != nil && !g.activeTetro.Fits(g.board) {
+		g.activeTetro.MoveUp()
+		g.lowestRow--
+		g.emitTetro(MovedEvent)
+	}
+	return fits
+}
 
-	g.updateUI()
-	time.Sleep(pauseForEffect)
+// removeClearedRows drops the lines above each cleared row down, then checks the tetro spawned meanwhile for a block out.
+// Rows are removed from the top down, so removing a row never moves the cleared rows below it.
+func (g *Game) removeClearedRows() {
+	for _, y := range g.clearedRows {
+		g.board.RemoveRow(y)
+	}
+	g.clearedRows = g.clearedRows[:0]
+	g.blockOut()
+}
 
-	// lines above drop down
-	for i := range g.stationaryBlocks {
-		_, blockY := g.stationaryBlocks[i].Coordinates()
-		if blockY < y {
-			g.stationaryBlocks[i].MoveDown()
-		}
+func (g *Game) updateUI() {
+	if !g.muted {
+		g.render()
 	}
+}
 
-	g.updateUI()
+// overHelp lists the keys that do something once the game is over, enter only goes back to the title with the menus
+func (g *Game) overHelp() string {
+	if g.menus {
+		return "R - Restart  ⏎ - Title  Esc - Quit"
+	}
+	return "R - Restart  Esc - Quit"
 }
 
-func (g *Game) updateUI() {
+// render sends the whole game state to the UI, showing only the blocks below the buffer
+func (g *Game) render() {
 	status := ui.Running
 	if g.pause {
 		status = ui.Pause
@@ -312,10 +752,30 @@ func (g *Game) updateUI() {
 		status = ui.GameOver
 	}
 
-	blocks := append(g.activeTetro.Blocks(), g.stationaryBlocks...)
-	g.ui.Update(blocks, g.tetroQueue, g.score, g.level, g.cleared, status)
+	var ghost []tetris.Block
+	if g.showGhost {
+		ghost = visible(g.activeTetro.Ghost(g.board))
+	}
+
+	blocks := visible(append(g.activeTetro.Blocks(), g.board.Blocks()...))
+	g.ui.ShowHighScores(g.highScoreTable())
+	g.ui.ShowMenu(g.menuView())
+	mode := g.mode.status(g)
+	mode.Results = g.results
+	if g.over {
+		mode.Help = g.overHelp()
+	}
+	g.ui.ShowMode(mode)
+	g.ui.Update(blocks, ghost, g.tetroQueue, g.heldTetro, g.score, g.level, g.cleared, g.action, status)
 }
 
-func (g *Game) resetMoveTimer() {
-	g.moveTimer = time.Now()
+// visible leaves out the blocks hidden in the buffer above the board
+func visible(blocks []tetris.Block) []tetris.Block {
+	shown := make([]tetris.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if _, y := block.Coordinates(); y >= 0 {
+			shown = append(shown, block)
+		}
+	}
+	return shown
 }
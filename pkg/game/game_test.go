@@ -1,6 +1,9 @@
 package game
 
 import (
+	"fmt"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -9,22 +12,16 @@ import (
 )
 
 func TestNewGame(t *testing.T) {
-	mockUI, cleanup, err := ui.NewUI(ui.Mock)
-	if err != nil {
-		t.Fatalf("failed to create mock UI: %v", err)
-	}
-	defer cleanup()
-
-	game := NewGame(mockUI)
+	game := newTestGame(t)
 
 	if game.ui == nil {
 		t.Error("NewGame() ui is nil")
 	}
-	if game.boardHeight != boardHeight {
-		t.Errorf("NewGame() boardHeight = %v, want %v", game.boardHeight, boardHeight)
+	if game.boardHeight != 20 {
+		t.Errorf("NewGame() boardHeight = %v, want %v", game.boardHeight, 20)
 	}
-	if game.boardWidth != boardWidth {
-		t.Errorf("NewGame() boardWidth = %v, want %v", game.boardWidth, boardWidth)
+	if game.boardWidth != 10 {
+		t.Errorf("NewGame() boardWidth = %v, want %v", game.boardWidth, 10)
 	}
 	if game.score != 0 {
 		t.Errorf("NewGame() score = %v, want %v", game.score, 0)
@@ -41,43 +38,83 @@ func TestNewGame(t *testing.T) {
 	if game.over != false {
 		t.Errorf("NewGame() over = %v, want %v", game.over, false)
 	}
-	if game.moveDelay != 500*time.Millisecond {
-		t.Errorf("NewGame() moveDelay = %v, want %v", game.moveDelay, moveDelay)
+	if game.options != DefaultOptions() {
+		t.Errorf("NewGame() options = %+v, want %+v", game.options, DefaultOptions())
+	}
+	if game.activeTetro == nil {
+		t.Error("NewGame() active tetro not dealt")
+	}
+	if len(game.tetroQueue) != 5 {
+		t.Errorf("NewGame() tetroQueue length = %v, want %v", len(game.tetroQueue), 5)
+	}
+	for i, tetro := range game.tetroQueue {
+		if tetro == nil {
+			t.Errorf("NewGame() tetroQueue[%d] not dealt", i)
+		}
+	}
+	if game.board == nil {
+		t.Fatal("NewGame() board is nil")
+	}
+	if game.board.Height() != game.boardHeight || game.board.Width() != game.boardWidth {
+		t.Errorf("NewGame() board size = %vx%v, want %vx%v", game.board.Height(), game.board.Width(), game.boardHeight, game.boardWidth)
+	}
+	if len(game.board.Blocks()) != 0 {
+		t.Error("NewGame() board should be empty at the start")
 	}
-	if len(game.tetroQueue) != tetroQueueSize {
-		t.Errorf("NewGame() tetroQueue length = %v, want %v", len(game.tetroQueue), tetroQueueSize)
+	if game.factory == nil {
+		t.Error("NewGame() factory is nil")
 	}
-	if len(game.stationaryBlocks) != 0 {
-		t.Error("NewGame() stationaryBlocks should be empty at the start")
+	if game.generator == nil {
+		t.Error("NewGame() generator not set")
+	}
+	if game.seed != 42 {
+		t.Errorf("NewGame() seed = %v, want %v", game.seed, 42)
+	}
+	if game.rng == nil {
+		t.Error("NewGame() rng is nil")
 	}
 	if game.done == nil {
 		t.Error("NewGame() done channel is nil")
 	}
+	if game.scoring == nil {
+		t.Error("NewGame() scoring is nil")
+	}
+
+	options := DefaultOptions()
+	options.Gravity = nil
+	if _, err := NewGame(game.ui, game.generator, 42, NewManualClock(), options); err == nil {
+		t.Error("NewGame() with invalid options expected error, got nil")
+	}
 }
 
-func TestGame_Start(t *testing.T) {
-	tetris.Init(tetris.Config{
-		SpawnX: tetroSpawnX,
-		SpawnY: tetroSpawnY,
-		StyleO: tetris.BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
-		StyleI: tetris.BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
-		StyleS: tetris.BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
-		StyleZ: tetris.BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
-		StyleL: tetris.BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
-		StyleJ: tetris.BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
-		StyleT: tetris.BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
-	})
+func TestNewGame_CustomBoard(t *testing.T) {
+	options := DefaultOptions()
+	options.BoardHeight, options.BoardWidth = 30, 16
+	options.SpawnY, options.QueueSize = 2, 3
+	game := newTestGameWith(t, options)
 
-	mockUI, cleanup, err := ui.NewUI(ui.Mock)
-	if err != nil {
-		t.Fatalf("failed to create mock UI: %v", err)
+	if game.board.Height() != 30 || game.board.Width() != 16 {
+		t.Errorf("NewGame() board size = %vx%v, want 16x30", game.board.Width(), game.board.Height())
+	}
+	if len(game.tetroQueue) != 3 {
+		t.Errorf("NewGame() tetroQueue length = %v, want 3", len(game.tetroQueue))
 	}
-	defer cleanup()
 
-	// assert for non-UI field access
-	mock := mockUI.(*ui.MockUI)
+	// the tetro spawns in the middle of the wider board, at the row asked for
+	state := game.activeTetro.State()
+	if state.X != 8 || state.Y != 2 {
+		t.Errorf("NewGame() tetro spawned at (%d,%d), want (8,2)", state.X, state.Y)
+	}
+
+	// games on a different board aren't ranked against ones by the default rules
+	if game.ranked() {
+		t.Error("ranked() = true for a game on a custom board, want false")
+	}
+}
 
-	game := NewGame(mockUI)
+func TestGame_Start(t *testing.T) {
+	game := newTestGame(t)
+	mock := game.ui.(*ui.MockUI)
 
 	done := game.Start()
 	if done == nil {
@@ -96,3 +133,481 @@ func TestGame_Start(t *testing.T) {
 		t.Error("Game did not receive stop signal within timeout")
 	}
 }
+
+// newTestGame creates a game ready to play without starting the game loop
+func newTestGame(t *testing.T) *Game {
+	return newTestGameWith(t, DefaultOptions())
+}
+
+// newTestGameWith creates a game played with the given options, ready to play without starting the game loop
+func newTestGameWith(t *testing.T, options Options) *Game {
+	mockUI, cleanup, err := ui.NewUI(ui.Mock)
+	if err != nil {
+		t.Fatalf("failed to create mock UI: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	generator, err := tetris.NewGenerator(tetris.Bag7Generator)
+	if err != nil {
+		t.Fatalf("failed to create generator: %v", err)
+	}
+
+	game, err := NewGame(mockUI, generator, 42, NewManualClock(), options)
+	if err != nil {
+		t.Fatalf("NewGame() unexpected error: %v", err)
+	}
+	return &game
+}
+
+// playTestGame plays a number of tetros with a mix of inputs, hard dropping some and letting the rest lock by themselves
+func playTestGame(game *Game, tetros int) {
+	keys := []ui.KeyPress{ui.KeyLeft, ui.KeyUp, ui.KeyRight, ui.KeyRight, ui.KeyDown, ui.KeyRotateLeft, ui.KeyHold, ui.KeyRotate180}
+	for i := 0; i < tetros && !game.Over(); i++ {
+		for j := 0; j <= i%4; j++ {
+			game.Press(keys[(i+j)%len(keys)])
+			game.Step(7)
+		}
+		if i%3 == 0 {
+			game.Press(ui.KeyHardDrop)
+		} else {
+			game.Step(90) // let the tetro lock by itself
+		}
+	}
+}
+
+func TestGame_HoldTetro(t *testing.T) {
+	game := newTestGame(t)
+
+	first := game.activeTetro
+	second := game.tetroQueue[0]
+	first.MoveDown()
+	first.MoveDown()
+
+	// first hold stores the active tetro and takes the next from the queue
+	game.holdTetro()
+	if fmt.Sprintf("%T", game.heldTetro) != fmt.Sprintf("%T", first) {
+		t.Errorf("holdTetro() held %T, want %T", game.heldTetro, first)
+	}
+	if game.activeTetro != second {
+		t.Error("holdTetro() did not take the next tetro from the queue")
+	}
+	if len(game.tetroQueue) != game.options.QueueSize {
+		t.Errorf("holdTetro() queue length = %d, want %d", len(game.tetroQueue), game.options.QueueSize)
+	}
+	spawned := first.Spawn()
+	for i, block := range game.heldTetro.Blocks() {
+		x, y := block.Coordinates()
+		spawnX, spawnY := spawned.Blocks()[i].Coordinates()
+		if x != spawnX || y != spawnY {
+			t.Errorf("held tetro block %d at (%d,%d), want spawn position (%d,%d)", i, x, y, spawnX, spawnY)
+		}
+	}
+
+	// hold can only be used once until the tetro locks
+	game.holdTetro()
+	if game.activeTetro != second {
+		t.Error("holdTetro() swapped twice before the tetro locked")
+	}
+
+	// once the tetro locks hold is available again, and swaps with the held tetro
+	game.nextTetro()
+	third := game.activeTetro
+	held := game.heldTetro
+	game.holdTetro()
+	if game.activeTetro != held {
+		t.Error("holdTetro() did not swap the held tetro back in")
+	}
+	if fmt.Sprintf("%T", game.heldTetro) != fmt.Sprintf("%T", third) {
+		t.Errorf("holdTetro() held %T, want %T", game.heldTetro, third)
+	}
+}
+
+func TestGame_SoftDrop(t *testing.T) {
+	game := newTestGame(t)
+
+	_, startY := game.activeTetro.Blocks()[0].Coordinates()
+	game.softDrop()
+	game.softDrop()
+
+	_, y := game.activeTetro.Blocks()[0].Coordinates()
+	if y != startY+2 {
+		t.Errorf("softDrop() moved tetro to y = %d, want %d", y, startY+2)
+	}
+	if game.score != 2*softDropPoints {
+		t.Errorf("softDrop() score = %d, want %d", game.score, 2*softDropPoints)
+	}
+	if len(game.board.Blocks()) != 0 {
+		t.Error("softDrop() should not lock the tetro")
+	}
+}
+
+func TestGame_HardDrop(t *testing.T) {
+	game := newTestGame(t)
+
+	dropped := game.activeTetro
+	next := game.tetroQueue[0]
+
+	// on an empty board the tetro drops until its lowest block reaches the floor
+	lowestY := 0
+	for _, block := range dropped.Blocks() {
+		_, y := block.Coordinates()
+		if y > lowestY {
+			lowestY = y
+		}
+	}
+	rows := game.boardHeight - 1 - lowestY
+
+	game.hardDrop()
+
+	if game.score != rows*hardDropPoints {
+		t.Errorf("hardDrop() score = %d, want %d", game.score, rows*hardDropPoints)
+	}
+	locked := game.board.Blocks()
+	if len(locked) != 4 {
+		t.Errorf("hardDrop() locked blocks = %d, want 4", len(locked))
+	}
+	maxY := 0
+	for _, block := range locked {
+		_, y := block.Coordinates()
+		if y > maxY {
+			maxY = y
+		}
+	}
+	if maxY != game.boardHeight-1 {
+		t.Errorf("hardDrop() locked tetro with lowest block at y = %d, want %d", maxY, game.boardHeight-1)
+	}
+	if game.activeTetro != next {
+		t.Error("hardDrop() did not take the next tetro from the queue")
+	}
+}
+
+func TestGame_ToggleGhost(t *testing.T) {
+	game := newTestGame(t)
+
+	if !game.showGhost {
+		t.Fatal("ghost should be shown by default")
+	}
+
+	game.processKeyPress(ui.KeyGhost)
+	if game.showGhost {
+		t.Error("KeyGhost did not hide the ghost")
+	}
+
+	game.processKeyPress(ui.KeyGhost)
+	if !game.showGhost {
+		t.Error("KeyGhost did not show the ghost again")
+	}
+}
+
+// run with -race to check inputs and gravity don't change the game state at the same time
+func TestGame_InputDuringGravity(t *testing.T) {
+	game := newTestGame(t)
+	mock := game.ui.(*ui.MockUI)
+	clock := game.clock.(*ManualClock)
+	done := game.Start()
+
+	// keep pressing keys while the clock runs through many gravity ticks
+	frames := make(chan struct{})
+	go func() {
+		defer close(frames)
+		clock.Advance(200 * frameDuration)
+	}()
+
+	keys := []ui.KeyPress{ui.KeyLeft, ui.KeyUp, ui.KeyRight, ui.KeyDown, ui.KeyRotateLeft, ui.KeyHardDrop, ui.KeyHold, ui.KeyGhost}
+	for i := 0; i < 200; i++ {
+		mock.SendKeyPress(keys[i%len(keys)])
+		time.Sleep(time.Millisecond)
+	}
+	<-frames
+
+	game.Stop()
+
+	select {
+	case <-done:
+	default:
+		t.Error("Stop() returned before the game was done")
+	}
+	if !mock.Stopped {
+		t.Error("Stop() did not stop the UI")
+	}
+	if game.Frames() == 0 {
+		t.Error("game did not play any frames")
+	}
+
+	// stopping again is safe once the game is done
+	game.Stop()
+}
+
+func TestGame_Step(t *testing.T) {
+	game := newTestGame(t)
+
+	_, startY := game.activeTetro.Blocks()[0].Coordinates()
+	game.options.Gravity = classicGravity
+	gravity := 30 // frames per row at level 0
+
+	// the tetro falls a row each time the gravity delay passes
+	game.Step(gravity - 1)
+	if _, y := game.activeTetro.Blocks()[0].Coordinates(); y != startY {
+		t.Errorf("Step(%d) moved tetro to y = %d before the gravity delay, want %d", gravity-1, y, startY)
+	}
+	game.Step(1)
+	if _, y := game.activeTetro.Blocks()[0].Coordinates(); y != startY+1 {
+		t.Errorf("Step(%d) moved tetro to y = %d, want %d", gravity, y, startY+1)
+	}
+	game.Step(3 * gravity)
+	if _, y := game.activeTetro.Blocks()[0].Coordinates(); y != startY+4 {
+		t.Errorf("Step(%d) moved tetro to y = %d, want %d", 4*gravity, y, startY+4)
+	}
+	if game.Frames() != 4*gravity {
+		t.Errorf("Frames() = %d, want %d", game.Frames(), 4*gravity)
+	}
+
+	// nothing moves while paused
+	game.Press(ui.KeyPause)
+	game.Step(10 * gravity)
+	if _, y := game.activeTetro.Blocks()[0].Coordinates(); y != startY+4 {
+		t.Errorf("Step() moved tetro to y = %d while paused, want %d", y, startY+4)
+	}
+	if game.Frames() != 4*gravity {
+		t.Errorf("Frames() = %d while paused, want %d", game.Frames(), 4*gravity)
+	}
+}
+
+func TestGame_StepClearsLines(t *testing.T) {
+	game := newTestGame(t)
+	bottom := game.boardHeight - 1
+
+	// fill the bottom row, apart from where the active tetro will land
+	landing := map[int]bool{}
+	for _, block := range game.activeTetro.Ghost(game.board) {
+		x, y := block.Coordinates()
+		if y == bottom {
+			landing[x] = true
+		}
+	}
+	var blocks []tetris.Block
+	for x := 0; x < game.boardWidth; x++ {
+		if !landing[x] {
+			blocks = append(blocks, tetris.NewBlock(x, bottom, "fill"))
+		}
+	}
+	game.board.Place(blocks)
+
+	// the line clears as soon as the tetro locks, the line above waits before dropping down
+	score := game.score
+	lowestY := lowestRow(game.activeTetro)
+	game.hardDrop()
+	score += (bottom - lowestY) * hardDropPoints
+
+	var above []bool
+	for x := 0; x < game.boardWidth; x++ {
+		above = append(above, !game.board.Free(x, bottom-1))
+	}
+
+	if game.Cleared() != 1 {
+		t.Fatalf("Cleared() = %d, want 1", game.Cleared())
+	}
+	score += guidelinePoints[tetris.NoSpin][1]
+	if game.board.Empty() {
+		score += perfectClearPoints[1] // the tetro was flat, so the whole board cleared
+	}
+	if game.Score() != score {
+		t.Errorf("Score() = %d, want %d", game.Score(), score)
+	}
+	if !game.board.RowEmpty(bottom) {
+		t.Error("cleared row should be empty while pausing for effect")
+	}
+	if game.playing() {
+		t.Error("player should not move the tetro while rows are removed")
+	}
+
+	game.Step(framesIn(pauseForEffect))
+	for x, filled := range above {
+		if filled == game.board.Free(x, bottom) {
+			t.Errorf("cell (%d,%d) filled = %v after the row above dropped down, want %v", x, bottom, !filled, filled)
+		}
+	}
+	if !game.playing() {
+		t.Error("player should move the tetro once rows are removed")
+	}
+}
+
+// fillColumns fills the rows from top down to the floor in the given columns
+func fillColumns(game *Game, top int, columns ...int) {
+	var blocks []tetris.Block
+	for y := top; y < game.boardHeight; y++ {
+		for _, x := range columns {
+			blocks = append(blocks, tetris.NewBlock(x, y, "stack"))
+		}
+	}
+	game.board.Place(blocks)
+}
+
+func TestGame_BlockOut(t *testing.T) {
+	game := newTestGame(t)
+
+	// the next tetro spawns where the stack already is
+	next := game.tetroQueue[0]
+	game.board.Place(next.Blocks())
+	game.nextTetro()
+
+	if !game.Over() {
+		t.Error("game isn't over after a tetro spawned overlapping the stack")
+	}
+}
+
+func TestGame_BlockOutAfterClear(t *testing.T) {
+	game := newTestGame(t)
+
+	// an I clears the bottom row, dropping a block in the buffer into where the next T spawns
+	game.activeTetro, _ = game.factory.NewTetro(tetris.ShapeI)
+	for game.activeTetro.CanMoveLeft(game.board) {
+		game.activeTetro.MoveLeft()
+	}
+	game.tetroQueue[0], _ = game.factory.NewTetro(tetris.ShapeT)
+	x, top := 0, game.boardHeight
+	for _, block := range game.tetroQueue[0].Blocks() {
+		if bx, by := block.Coordinates(); by < top {
+			x, top = bx, by
+		}
+	}
+	game.board.Place([]tetris.Block{tetris.NewBlock(x, top-1, "stack")})
+	fillColumns(game, game.boardHeight-1, 4, 5, 6, 7, 8, 9)
+	game.hardDrop()
+
+	if game.Over() || !game.activeTetro.Fits(game.board) {
+		t.Fatal("tetro spawned while the cleared row waits to be removed doesn't fit")
+	}
+	game.Step(framesIn(pauseForEffect))
+	if !game.Over() {
+		t.Error("game isn't over after the stack dropped into the tetro at the spawn position")
+	}
+}
+
+func TestGame_LockOut(t *testing.T) {
+	game := newTestGame(t)
+	mockUI := game.ui.(*ui.MockUI)
+
+	// a T locks partly in the buffer on top of a stack at the left wall, with its nub out of sight
+	game.activeTetro, _ = game.factory.NewTetro(tetris.ShapeT)
+	for game.activeTetro.CanMoveLeft(game.board) {
+		game.activeTetro.MoveLeft()
+	}
+	fillColumns(game, 1, 0, 1, 2)
+	game.hardDrop()
+
+	if game.Over() {
+		t.Fatal("game is over after a tetro locked with blocks in sight")
+	}
+	if game.board.Free(1, -1) {
+		t.Error("block locked in the buffer wasn't kept")
+	}
+	for _, block := range mockUI.Blocks {
+		if x, y := block.Coordinates(); y < 0 {
+			t.Errorf("block at (%d,%d) in the buffer was drawn", x, y)
+		}
+	}
+
+	// the next one locks entirely in the buffer, on top of a stack reaching the top under the spawn position
+	fillColumns(game, 0, 3, 4, 5, 6, 7, 8)
+	for !lockedOut(game.activeTetro.Blocks()) {
+		game.activeTetro.MoveUp()
+	}
+	game.hardDrop()
+
+	if !game.Over() {
+		t.Error("game isn't over after a tetro locked entirely in the buffer")
+	}
+}
+
+func TestGame_Restart(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  func(t *testing.T, game *Game)
+		restart bool
+	}{
+		{"game over", func(t *testing.T, game *Game) { endTestGame(t, game) }, true},
+		{"paused", func(t *testing.T, game *Game) {
+			playTestGame(game, 5)
+			game.Press(ui.KeyPause)
+		}, true},
+		{"playing", func(t *testing.T, game *Game) { playTestGame(game, 5) }, false},
+		{"naming a high score", func(t *testing.T, game *Game) {
+			scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+			if err != nil {
+				t.Fatalf("LoadHighScores() unexpected error: %v", err)
+			}
+			game.UseHighScores(scores)
+			endTestGame(t, game)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			var events []Event
+			game.Subscribe(func(event Event) { events = append(events, event) })
+			tt.before(t, game)
+			frames := game.Frames()
+
+			game.Press(ui.KeyRestart)
+
+			if !tt.restart {
+				if game.Frames() != frames || game.Seed() != 42 {
+					t.Errorf("game restarted, at frame %d with seed %d", game.Frames(), game.Seed())
+				}
+				return
+			}
+			if game.Over() || game.pause || game.Frames() != 0 || game.Score() != 0 || game.Cleared() != 0 || game.heldTetro != nil {
+				t.Errorf("restarted game isn't fresh: over %v, paused %v, frame %d, score %d, %d lines, held %v",
+					game.Over(), game.pause, game.Frames(), game.Score(), game.Cleared(), game.heldTetro)
+			}
+			if len(game.board.Blocks()) != 0 {
+				t.Errorf("restarted game's board has %d blocks, want none", len(game.board.Blocks()))
+			}
+			if game.Seed() == 42 {
+				t.Error("restarted game has the same seed")
+			}
+
+			// observers carry over to the new game, and are told its seed
+			got := eventTypes(events[len(events)-2:])
+			if want := []EventType{RestartedEvent, SpawnedEvent}; !reflect.DeepEqual(got, want) {
+				t.Errorf("last events = %v, want %v", got, want)
+			}
+			if seed := events[len(events)-2].Seed; seed != game.Seed() {
+				t.Errorf("restarted event seed = %d, want %d", seed, game.Seed())
+			}
+
+			// the restarted game plays out the same as a new game from its seed
+			generator, _ := tetris.NewGenerator(tetris.Bag7Generator)
+			fresh, err := NewGame(game.ui, generator, game.Seed(), NewManualClock(), DefaultOptions())
+			if err != nil {
+				t.Fatalf("NewGame() unexpected error: %v", err)
+			}
+			playTestGame(game, 20)
+			playTestGame(&fresh, 20)
+			if game.Score() != fresh.Score() || !reflect.DeepEqual(game.board.Blocks(), fresh.board.Blocks()) {
+				t.Error("restarted game played out differently to a new game with the same seed")
+			}
+		})
+	}
+}
+
+func TestGame_RestartEndsRecording(t *testing.T) {
+	game := newTestGame(t)
+	replay := game.Record()
+	endTestGame(t, game)
+	frames, score := game.Frames(), game.Score()
+
+	game.Press(ui.KeyRestart)
+	playTestGame(game, 5)
+
+	if replay.Frames != frames || replay.Score != score {
+		t.Errorf("replay result = frame %d, score %d, want the first game's %d, %d", replay.Frames, replay.Score, frames, score)
+	}
+	for _, input := range replay.Inputs {
+		if input.Key == ui.KeyRestart || input.Frame > frames {
+			t.Errorf("replay recorded %+v from after the restart", input)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	rowFraction = 1 << 16          // gravity is measured in fractions of a row, so tetros can fall less than a row per frame
+	maxGravity  = 20 * rowFraction // 20G and faster, the tetro drops onto the stack in a single frame, however tall the board
+)
+
+// Gravity decides how fast the active tetro falls at each level
+type Gravity interface {
+	// Speed returns how far the tetro falls each frame, in 1/65536ths of a row
+	Speed(level int) int
+}
+
+// gravityNames maps the names used on the command line to each built in gravity table
+var gravityNames = map[string]func() Gravity{
+	"guideline": func() Gravity { return guidelineGravity{} },
+	"nes":       func() Gravity { return nesGravity },
+	"classic":   func() Gravity { return classicGravity },
+}
+
+// ParseGravity returns the gravity for a name such as "guideline", or for a custom table given as a
+// comma separated list of frames per row for each level, e.g. "48,43,38". Fractions of a frame are allowed,
+// "0.05" is 20 rows per frame. Levels past the end of a custom table keep the last speed.
+func ParseGravity(name string) (Gravity, error) {
+	if newGravity, ok := gravityNames[name]; ok {
+		return newGravity(), nil
+	}
+
+	var framesPerRow []float64
+	for _, field := range strings.Split(name, ",") {
+		frames, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || frames <= 0 {
+			return nil, fmt.Errorf("unknown gravity: %q", name)
+		}
+		framesPerRow = append(framesPerRow, frames)
+	}
+	return NewTableGravity(framesPerRow), nil
+}
+
+// GravityName returns the name of the gravity as ParseGravity accepts it, so it can be saved and parsed again later.
+// Only built in gravity and tables created with NewTableGravity have names.
+func GravityName(gravity Gravity) (string, error) {
+	switch g := gravity.(type) {
+	case guidelineGravity:
+		return "guideline", nil
+	case *tableGravity:
+		if Gravity(g) == nesGravity {
+			return "nes", nil
+		}
+		if Gravity(g) == classicGravity {
+			return "classic", nil
+		}
+		fields := make([]string, len(g.framesPerRow))
+		for i, frames := range g.framesPerRow {
+			fields[i] = strconv.FormatFloat(frames, 'g', -1, 64)
+		}
+		return strings.Join(fields, ","), nil
+	default:
+		return "", fmt.Errorf("gravity %T has no name", gravity)
+	}
+}
+
+// NewTableGravity creates gravity from a table of frames per row for each level, starting at level 0
+func NewTableGravity(framesPerRow []float64) Gravity {
+	speeds := make([]int, len(framesPerRow))
+	for i, frames := range framesPerRow {
+		speeds[i] = speedFromFramesPerRow(frames)
+	}
+	return &tableGravity{framesPerRow: framesPerRow, speeds: speeds}
+}
+
+type tableGravity struct {
+	framesPerRow []float64
+	speeds       []int
+}
+
+func (t *tableGravity) Speed(level int) int {
+	if level >= len(t.speeds) {
+		level = len(t.speeds) - 1
+	}
+	return t.speeds[level]
+}
+
+// guidelineGravity follows the guideline curve, where each row takes (0.8 - (level-1) * 0.007)^(level-1) seconds.
+// Levels in the guideline start at 1, so level 0 here is level 1 there. The curve reaches 20G at level 18, level 19 there.
+type guidelineGravity struct{}
+
+func (guidelineGravity) Speed(level int) int {
+	seconds := math.Pow(0.8-float64(level)*0.007, float64(level))
+	return speedFromFramesPerRow(seconds * frameRate)
+}
+
+// nesGravity is the NTSC NES table of frames per row, which tops out at 1 row per frame from level 29
+var nesGravity = NewTableGravity([]float64{
+	48, 43, 38, 33, 28, 23, 18, 13, 8, 6, // levels 0-9
+	5, 5, 5, 4, 4, 4, 3, 3, 3, // levels 10-18
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, // levels 19-28
+	1, // level 29 onwards
+})
+
+// classicGravity is the original gravity of this game, a row every 500ms less 20ms per level, up to level 20
+var classicGravity = func() Gravity {
+	framesPerRow := make([]float64, 21)
+	for level := range framesPerRow {
+		framesPerRow[level] = float64(500-20*level) * frameRate / 1000
+	}
+	return NewTableGravity(framesPerRow)
+}()
+
+// speedFromFramesPerRow converts frames per row to fractions of a row per frame.
+// It rounds up, so a whole number of frames per row moves the tetro on exactly that frame.
+// Anything faster than 20G, including no time at all, is capped at 20G.
+func speedFromFramesPerRow(frames float64) int {
+	speed := math.Ceil(rowFraction / frames)
+	if frames <= 0 || !(speed < maxGravity) {
+		return maxGravity
+	}
+	return int(speed)
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestParseGravity(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       int
+		expected    int
+		expectError bool
+	}{
+		{"guideline", 0, speedFromFramesPerRow(60), false},
+		{"nes", 0, speedFromFramesPerRow(48), false},
+		{"classic", 0, speedFromFramesPerRow(30), false},
+		{"48,43,38", 1, speedFromFramesPerRow(43), false},
+		{"48, 43, 38", 10, speedFromFramesPerRow(38), false},
+		{"0.05", 0, maxGravity, false},
+		{"tgm", 0, 0, true},
+		{"48,,38", 0, 0, true},
+		{"0", 0, 0, true},
+		{"-1", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gravity, err := ParseGravity(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseGravity(%q) expected error, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGravity(%q) unexpected error: %v", tt.name, err)
+			}
+			if speed := gravity.Speed(tt.level); speed != tt.expected {
+				t.Errorf("ParseGravity(%q) Speed(%d) = %d, want %d", tt.name, tt.level, speed, tt.expected)
+			}
+
+			// the name parses back to the same gravity
+			name, err := GravityName(gravity)
+			if err != nil {
+				t.Fatalf("GravityName() unexpected error: %v", err)
+			}
+			again, err := ParseGravity(name)
+			if err != nil {
+				t.Fatalf("ParseGravity(%q) unexpected error: %v", name, err)
+			}
+			if speed := again.Speed(tt.level); speed != tt.expected {
+				t.Errorf("ParseGravity(GravityName()) Speed(%d) = %d, want %d", tt.level, speed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGravity_Speed(t *testing.T) {
+	tests := []struct {
+		name     string
+		gravity  Gravity
+		level    int
+		expected int
+	}{
+		{"guideline level 0 is a row a second", guidelineGravity{}, 0, speedFromFramesPerRow(60)},
+		{"guideline reaches 20G at level 18", guidelineGravity{}, 18, maxGravity},
+		{"guideline stays at 20G", guidelineGravity{}, 50, maxGravity},
+		{"guideline stays at 20G once the curve goes negative", guidelineGravity{}, 115, maxGravity},
+		{"nes level 9", nesGravity, 9, speedFromFramesPerRow(6)},
+		{"nes level 29 is a row a frame", nesGravity, 29, rowFraction},
+		{"nes stays at a row a frame", nesGravity, 99, rowFraction},
+		{"classic level 1 is a row every 480ms", classicGravity, 1, speedFromFramesPerRow(28.8)},
+		{"classic tops out at level 20", classicGravity, 25, speedFromFramesPerRow(6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if speed := tt.gravity.Speed(tt.level); speed != tt.expected {
+				t.Errorf("Speed(%d) = %d, want %d", tt.level, speed, tt.expected)
+			}
+		})
+	}
+
+	// the guideline curve never slows down, and level 18 is the first at 20G
+	previous := 0
+	for level := 0; level < 30; level++ {
+		speed := guidelineGravity{}.Speed(level)
+		if speed < previous {
+			t.Errorf("guideline Speed(%d) = %d, slower than the level before at %d", level, speed, previous)
+		}
+		if level < 18 && speed >= maxGravity {
+			t.Errorf("guideline Speed(%d) = %d, 20G before level 18", level, speed)
+		}
+		previous = speed
+	}
+}
+
+func TestGame_FractionalGravity(t *testing.T) {
+	tests := []struct {
+		name         string
+		framesPerRow float64
+		frames       int
+		expectedRows int
+	}{
+		{"less than a row a frame", 2.5, 4, 1},
+		{"fractions add up", 2.5, 5, 2},
+		{"many rows a frame", 0.5, 3, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			game.options.Gravity = NewTableGravity([]float64{tt.framesPerRow})
+			startY := lowestRow(game.activeTetro)
+
+			game.Step(tt.frames)
+
+			if rows := lowestRow(game.activeTetro) - startY; rows != tt.expectedRows {
+				t.Errorf("tetro fell %d rows in %d frames, want %d", rows, tt.frames, tt.expectedRows)
+			}
+		})
+	}
+}
+
+func TestGame_20GSpawnsOnTheStack(t *testing.T) {
+	game := newTestGame(t)
+	game.options.Gravity = NewTableGravity([]float64{0.05})
+
+	// each new tetro lands on the stack as soon as it spawns, without waiting for a frame
+	game.hardDrop()
+	if game.activeTetro.CanMoveDown(game.board) {
+		t.Error("tetro spawned at 20G should already be resting on the stack")
+	}
+
+	// the same goes for a tetro coming out of hold
+	game.holdTetro()
+	if game.activeTetro.CanMoveDown(game.board) {
+		t.Error("tetro taken from hold at 20G should already be resting on the stack")
+	}
+}
+
+func TestGame_20GOnATallBoard(t *testing.T) {
+	options := DefaultOptions()
+	options.BoardHeight = maxBoardSize
+	options.Gravity = NewTableGravity([]float64{0.05})
+	game := newTestGameWith(t, options)
+
+	// lifted to the top of the buffer, the tetro still reaches the floor in a single frame
+	for lowestRow(game.activeTetro) > -bufferRows {
+		game.activeTetro.MoveUp()
+	}
+	game.Step(1)
+	if lowest := lowestRow(game.activeTetro); lowest != game.boardHeight-1 {
+		t.Errorf("tetro fell to row %d in a frame at 20G, want the floor at row %d", lowest, game.boardHeight-1)
+	}
+}
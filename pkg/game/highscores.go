@@ -0,0 +1,234 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+const (
+	// HighScoresVersion is the version of the high score file written by this version of the game
+	HighScoresVersion = 1
+	maxHighScores     = 10 // scores kept for each mode
+	nameLength        = 3  // players enter their initials
+	defaultName       = "AAA"
+)
+
+// HighScore is a game good enough to make the high score table
+type HighScore struct {
+	Name     string        `json:"name"`
+	Score    int           `json:"score"`
+	Level    int           `json:"level"`
+	Lines    int           `json:"lines"`
+	Duration time.Duration `json:"duration"`
+	Seed     int64         `json:"seed"`
+	Date     time.Time     `json:"date"`
+}
+
+// HighScores is the table of best games for each mode, kept in a file between games
+type HighScores struct {
+	Version int                    `json:"version"`
+	Modes   map[string][]HighScore `json:"modes"` // by the mode's name, best first
+	path    string
+}
+
+// LoadHighScores reads the high score file at path. A missing file is an empty table, the file is created when first saved.
+func LoadHighScores(path string) (*HighScores, error) {
+	scores := &HighScores{Version: HighScoresVersion, Modes: map[string][]HighScore{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return scores, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, scores); err != nil {
+		return nil, fmt.Errorf("reading high scores: %w", err)
+	}
+	if scores.Version != HighScoresVersion {
+		return nil, fmt.Errorf("unsupported high scores version %d, want %d", scores.Version, HighScoresVersion)
+	}
+	if scores.Modes == nil {
+		scores.Modes = map[string][]HighScore{}
+	}
+	return scores, nil
+}
+
+// Save writes the table back to the file it was loaded from
+func (h *HighScores) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, append(data, '\n'), 0o644)
+}
+
+// Top returns the best games of a mode, best first
+func (h *HighScores) Top(mode ModeType) []HighScore {
+	return h.Modes[mode.String()]
+}
+
+// Rank returns the row a score would take in the mode's table, or -1 if it isn't good enough.
+// A score that ties with one already in the table goes below it, the first to get there keeps their place.
+func (h *HighScores) Rank(mode ModeType, score HighScore) int {
+	top := h.Top(mode)
+	rank := sort.Search(len(top), func(i int) bool { return mode.better(score, top[i]) })
+	if rank >= maxHighScores {
+		return -1
+	}
+	return rank
+}
+
+// Add puts a score in the mode's table, dropping the lowest if the table is full, and returns its row or -1 if it
+// isn't good enough
+func (h *HighScores) Add(mode ModeType, score HighScore) int {
+	rank := h.Rank(mode, score)
+	if rank < 0 {
+		return rank
+	}
+
+	top := append(h.Top(mode), HighScore{})
+	copy(top[rank+1:], top[rank:])
+	top[rank] = score
+	if len(top) > maxHighScores {
+		top = top[:maxHighScores]
+	}
+	h.Modes[mode.String()] = top
+	return rank
+}
+
+// Table returns the mode's table for the UI, with the given row highlighted, or -1 for none
+func (h *HighScores) Table(mode ModeType, entry int) *ui.HighScoreTable {
+	return highScoreTable(mode, h.Top(mode), entry)
+}
+
+func highScoreTable(mode ModeType, scores []HighScore, entry int) *ui.HighScoreTable {
+	name := mode.String()
+	table := &ui.HighScoreTable{
+		Title: "High Scores - " + strings.ToUpper(name[:1]) + name[1:],
+		Entry: entry,
+	}
+	for _, score := range scores {
+		table.Rows = append(table.Rows, ui.HighScoreRow{
+			Name:     score.Name,
+			Score:    score.Score,
+			Level:    score.Level,
+			Lines:    score.Lines,
+			Duration: score.Duration,
+			Seed:     score.Seed,
+			Date:     score.Date,
+		})
+	}
+	return table
+}
+
+// UseHighScores keeps the game's score in a high score table, shown once the game is over after any name entry
+func (g *Game) UseHighScores(scores *HighScores) {
+	g.highScores = scores
+}
+
+// highScore is the game's result as an entry for the high score table
+func (g *Game) highScore() HighScore {
+	return HighScore{
+		Name:     string(g.name),
+		Score:    g.score,
+		Level:    g.level,
+		Lines:    g.cleared,
+		Duration: time.Duration(g.frames) * frameDuration,
+		Seed:     g.seed,
+		Date:     g.endedAt,
+	}
+}
+
+// showHighScores starts name entry once the game is over if a ranked score makes the table, otherwise it shows the table
+func (g *Game) showHighScores() {
+	if g.highScores == nil {
+		return
+	}
+	g.endedAt = time.Now()
+	g.name = []byte(defaultName)
+	g.nameCursor = 0
+	g.naming = g.ranked() && g.highScores.Rank(g.options.Mode, g.highScore()) >= 0
+}
+
+// enterName handles a key press while the player enters their name: up and down change the letter,
+// left and right move between letters and enter saves the score
+func (g *Game) enterName(keypress ui.KeyPress) {
+	switch keypress {
+	case ui.KeyUp:
+		g.name[g.nameCursor] = 'A' + (g.name[g.nameCursor]-'A'+1)%26
+	case ui.KeyDown:
+		g.name[g.nameCursor] = 'A' + (g.name[g.nameCursor]-'A'+25)%26
+	case ui.KeyLeft:
+		if g.nameCursor > 0 {
+			g.nameCursor--
+		}
+	case ui.KeyRight:
+		if g.nameCursor < nameLength-1 {
+			g.nameCursor++
+		}
+	case ui.KeyEnter:
+		g.naming = false
+		g.newScore = g.highScores.Add(g.options.Mode, g.highScore())
+		if err := g.highScores.Save(); err != nil {
+			g.action = "Couldn't save high scores" // frames have stopped, so it stays until the game is quit
+		}
+	default:
+		return
+	}
+	g.updateUI()
+}
+
+// highScoreTable is the table to show over the game, nil while the game is still going or another menu is shown
+func (g *Game) highScoreTable() *ui.HighScoreTable {
+	if g.highScores == nil {
+		return nil
+	}
+	if g.menu != nil {
+		if g.menu.screen != highScoresMenu {
+			return nil
+		}
+		table := g.highScores.Table(g.settings.options.Mode, -1)
+		table.Help = "Left/Right - Mode  Esc - Back"
+		return table
+	}
+	if !g.over {
+		return nil
+	}
+	table := g.highScores.Table(g.options.Mode, g.newScore)
+	if g.naming {
+		table = g.namingTable()
+	}
+	table.Text = g.results
+	table.Help = g.overHelp()
+	return table
+}
+
+// namingTable is the high score table with the player's score in the row it will take, under the name being entered
+func (g *Game) namingTable() *ui.HighScoreTable {
+	score := g.highScore()
+	rank := g.highScores.Rank(g.options.Mode, score)
+	top := append([]HighScore(nil), g.highScores.Top(g.options.Mode)...)
+	top = append(top[:rank], append([]HighScore{score}, top[rank:]...)...)
+	if len(top) > maxHighScores {
+		top = top[:maxHighScores]
+	}
+
+	table := highScoreTable(g.options.Mode, top, rank)
+	table.Naming = true
+	table.Cursor = g.nameCursor
+	return table
+}
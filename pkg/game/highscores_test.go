@@ -0,0 +1,202 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// fullHighScores returns a table of maxHighScores scores, 1000 points apart, from 10000 down
+func fullHighScores(t *testing.T) *HighScores {
+	scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	for i := 0; i < maxHighScores; i++ {
+		scores.Add(MarathonMode, HighScore{Name: "OLD", Score: (maxHighScores - i) * 1000})
+	}
+	return scores
+}
+
+// endTestGame hard drops tetros until the game is over
+func endTestGame(t *testing.T, game *Game) {
+	for i := 0; i < 100 && !game.Over(); i++ {
+		game.Press(ui.KeyHardDrop)
+		game.Step(10)
+	}
+	if !game.Over() {
+		t.Fatal("game didn't end")
+	}
+}
+
+func TestHighScores_Add(t *testing.T) {
+	tests := []struct {
+		name  string
+		score int
+		want  int
+	}{
+		{"best", 20000, 0},
+		{"between", 5500, 5},
+		{"tie goes below", 5000, 6},
+		{"last place", 1500, 9},
+		{"too low", 1000, -1},
+		{"nothing", 0, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := fullHighScores(t)
+
+			if rank := scores.Rank(MarathonMode, HighScore{Score: tt.score}); rank != tt.want {
+				t.Errorf("Rank() = %d, want %d", rank, tt.want)
+			}
+			rank := scores.Add(MarathonMode, HighScore{Name: "NEW", Score: tt.score})
+			if rank != tt.want {
+				t.Errorf("Add() = %d, want %d", rank, tt.want)
+			}
+
+			top := scores.Top(MarathonMode)
+			if len(top) != maxHighScores {
+				t.Errorf("table has %d scores, want %d", len(top), maxHighScores)
+			}
+			if rank >= 0 && top[rank].Name != "NEW" {
+				t.Errorf("row %d = %+v, want the new score", rank, top[rank])
+			}
+			for i := 1; i < len(top); i++ {
+				if top[i].Score > top[i-1].Score {
+					t.Errorf("row %d scores %d, more than %d above it", i, top[i].Score, top[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadHighScores(t *testing.T) {
+	dir := t.TempDir()
+
+	scores, err := LoadHighScores(filepath.Join(dir, "tetris", "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() of a missing file unexpected error: %v", err)
+	}
+	if len(scores.Top(MarathonMode)) != 0 {
+		t.Error("LoadHighScores() of a missing file isn't empty")
+	}
+
+	scores.Add(MarathonMode, HighScore{Name: "ABC", Score: 100, Level: 1, Lines: 12, Seed: 42})
+	if err := scores.Save(); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	loaded, err := LoadHighScores(filepath.Join(dir, "tetris", "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Top(MarathonMode), scores.Top(MarathonMode)) {
+		t.Errorf("LoadHighScores() = %+v, want %+v", loaded.Top(MarathonMode), scores.Top(MarathonMode))
+	}
+
+	for name, contents := range map[string]string{
+		"unknown version": `{"version": 99}`,
+		"garbage":         `not high scores`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadHighScores(path); err == nil {
+			t.Errorf("LoadHighScores() of %s expected error, got nil", name)
+		}
+	}
+}
+
+func TestGame_EnterHighScore(t *testing.T) {
+	scores := fullHighScores(t)
+	scores.Modes["marathon"] = scores.Modes["marathon"][:3] // leave room for the test game's score
+
+	game := newTestGame(t)
+	game.UseHighScores(scores)
+	mockUI := game.ui.(*ui.MockUI)
+
+	game.Step(1)
+	if mockUI.HighScores != nil {
+		t.Error("high scores shown while the game is still going")
+	}
+
+	endTestGame(t, game)
+	if !game.naming {
+		t.Fatal("game over with a high score didn't ask for a name")
+	}
+	if table := mockUI.HighScores; table == nil || !table.Naming || table.Entry != 3 || table.Rows[3].Name != defaultName {
+		t.Fatalf("table shown = %+v, want the new score being named in row 3", table)
+	}
+
+	// game keys are used to enter the name, and don't play on
+	for _, key := range []ui.KeyPress{ui.KeyUp, ui.KeyRight, ui.KeyDown, ui.KeyRight, ui.KeyRight, ui.KeyUp, ui.KeyUp, ui.KeyPause} {
+		game.Press(key)
+	}
+	if game.pause {
+		t.Error("pause key paused the game while entering a name")
+	}
+	if table := mockUI.HighScores; table.Cursor != nameLength-1 || table.Rows[3].Name != "BZC" {
+		t.Errorf("table shown = %+v, want name BZC with the cursor on the last letter", table)
+	}
+
+	game.Press(ui.KeyEnter)
+	if game.naming {
+		t.Error("enter didn't finish the name")
+	}
+	if table := mockUI.HighScores; table.Naming || table.Entry != 3 {
+		t.Errorf("table shown = %+v, want the new score highlighted", table)
+	}
+
+	loaded, err := LoadHighScores(scores.path)
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	top := loaded.Top(MarathonMode)
+	if len(top) != 4 {
+		t.Fatalf("saved table has %d scores, want 4", len(top))
+	}
+	want := HighScore{Name: "BZC", Score: game.Score(), Level: game.Level(), Lines: game.Cleared(), Duration: top[3].Duration, Seed: 42, Date: top[3].Date}
+	if top[3] != want || top[3].Duration <= 0 || top[3].Date.IsZero() {
+		t.Errorf("saved score = %+v, want %+v after %d frames", top[3], want, game.Frames())
+	}
+}
+
+func TestGame_HighScoreNotGoodEnough(t *testing.T) {
+	scores := fullHighScores(t)
+	game := newTestGame(t)
+	game.UseHighScores(scores)
+
+	endTestGame(t, game)
+
+	if game.naming {
+		t.Error("asked for a name for a score that didn't make the table")
+	}
+	if table := game.ui.(*ui.MockUI).HighScores; table == nil || table.Naming || table.Entry != -1 || len(table.Rows) != maxHighScores {
+		t.Errorf("table shown = %+v, want the full table with nothing highlighted", table)
+	}
+}
+
+func TestGame_HighScoreSaveFails(t *testing.T) {
+	// the directory for the high scores is a file, so they can't be saved
+	dir := t.TempDir()
+	scores, err := LoadHighScores(filepath.Join(dir, "tetris", "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tetris"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	game := newTestGame(t)
+	game.UseHighScores(scores)
+	endTestGame(t, game)
+	game.Press(ui.KeyEnter)
+
+	if game.action != "Couldn't save high scores" {
+		t.Errorf("action = %q, want the failure shown", game.action)
+	}
+}
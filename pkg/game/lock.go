@@ -0,0 +1,113 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+)
+
+const (
+	MoveResetLock LockType = iota
+	StepResetLock
+	InfiniteLock
+)
+
+// maxLockResets is the number of times moving or rotating can reset the lock delay with MoveResetLock
+const maxLockResets = 15
+
+// LockType decides what resets the lock delay, i.e. how long a tetro can rest on the stack before it locks in place.
+// With every type the delay starts again when the tetro falls below the lowest row it has reached.
+//
+// MoveResetLock follows the guideline, moving or rotating resets the delay, but only 15 times, and the next move locks the tetro.
+// StepResetLock only resets the delay when the tetro falls a row, moving and rotating don't.
+// InfiniteLock resets the delay on every move or rotation, so a tetro can be kept moving forever.
+type LockType int
+
+// lockNames maps the names used on the command line to each lock type
+var lockNames = map[string]LockType{
+	"move":     MoveResetLock,
+	"step":     StepResetLock,
+	"infinite": InfiniteLock,
+}
+
+// ParseLockType returns the lock type for a name such as "move"
+func ParseLockType(name string) (LockType, error) {
+	lockType, ok := lockNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown lock type: %q", name)
+	}
+	return lockType, nil
+}
+
+// String returns the name of the lock type as used on the command line, e.g. "move"
+func (t LockType) String() string {
+	for name, lockType := range lockNames {
+		if lockType == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("LockType(%d)", int(t))
+}
+
+// checkLock counts down the lock delay while the active tetro rests on the stack, locking it once the delay runs out
+func (g *Game) checkLock() {
+	if g.activeTetro.CanMoveDown(g.board) {
+		return
+	}
+
+	g.lockTimer++
+	if g.lockTimer >= framesIn(g.options.LockDelay) {
+		g.lockTetro()
+	}
+}
+
+// movedDown starts the lock delay again if the active tetro has fallen to a new lowest row, or has fallen at all with StepResetLock
+func (g *Game) movedDown() {
+	lowest := lowestRow(g.activeTetro)
+	if lowest > g.lowestRow {
+		g.lowestRow = lowest
+		g.lockResets = 0
+		g.lockTimer = 0
+	} else if g.options.LockType == StepResetLock {
+		g.lockTimer = 0
+	}
+}
+
+// moved starts the lock delay again after the player moves or rotates the active tetro while it rests on the stack,
+// as long as the lock type allows it. With MoveResetLock, a move on the stack once the resets are used up locks the tetro.
+func (g *Game) moved() {
+	if g.activeTetro.CanMoveDown(g.board) {
+		return
+	}
+
+	switch g.options.LockType {
+	case MoveResetLock:
+		if g.lockResets == maxLockResets {
+			g.lockTetro()
+			return
+		}
+		g.lockResets++
+		g.lockTimer = 0
+	case InfiniteLock:
+		g.lockTimer = 0
+	}
+}
+
+// resetLock starts the lock delay from scratch for a new active tetro
+func (g *Game) resetLock() {
+	g.lockTimer = 0
+	g.lockResets = 0
+	g.lowestRow = lowestRow(g.activeTetro)
+}
+
+// lowestRow returns the row of the tetro's lowest block
+func lowestRow(tetro tetris.Tetro) int {
+	lowest := 0
+	for i, block := range tetro.Blocks() {
+		_, y := block.Coordinates()
+		if i == 0 || y > lowest {
+			lowest = y
+		}
+	}
+	return lowest
+}
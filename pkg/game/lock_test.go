@@ -0,0 +1,147 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+func TestGame_LockDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockType LockType
+		lockedAt int // frame the tetro locks on, 0 if it never locks
+	}{
+		// moving doesn't reset the delay, so the tetro locks once the delay runs out
+		{"step reset", StepResetLock, 30},
+		// moves every 10 frames reset the delay 15 times, the 16th move on frame 160 locks the tetro
+		{"move reset", MoveResetLock, 161},
+		// moves every 10 frames keep resetting the delay
+		{"infinite", InfiniteLock, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			game.options.LockType = tt.lockType
+
+			// rest the tetro on the floor
+			for game.activeTetro.CanMoveDown(game.board) {
+				game.moveTetroDown()
+			}
+			resting := game.activeTetro
+
+			// keep moving the tetro left and right along the floor
+			keys := []ui.KeyPress{ui.KeyLeft, ui.KeyRight}
+			for frame := 1; frame <= 600; frame++ {
+				game.Step(1)
+
+				locked := game.activeTetro != resting
+				if locked != (frame == tt.lockedAt) && (tt.lockedAt == 0 || frame <= tt.lockedAt) {
+					t.Fatalf("frame %d: locked = %v, want lock on frame %d", frame, locked, tt.lockedAt)
+				}
+				if locked {
+					return
+				}
+
+				if frame%10 == 0 {
+					game.Press(keys[frame/10%2])
+				}
+			}
+
+			if tt.lockedAt != 0 {
+				t.Errorf("tetro did not lock, want lock on frame %d", tt.lockedAt)
+			}
+		})
+	}
+}
+
+func TestGame_LockAfterMoveResets(t *testing.T) {
+	game := newTestGame(t)
+	for game.activeTetro.CanMoveDown(game.board) {
+		game.moveTetroDown()
+	}
+	resting := game.activeTetro
+
+	// 15 moves on the stack reset the delay, however quickly they're made
+	keys := []ui.KeyPress{ui.KeyLeft, ui.KeyRight}
+	for i := 0; i < maxLockResets; i++ {
+		game.Press(keys[i%2])
+	}
+	if game.activeTetro != resting || game.lockResets != maxLockResets {
+		t.Fatalf("tetro locked or used %d resets after %d moves, want it still moving with every reset used", game.lockResets, maxLockResets)
+	}
+
+	// the 16th locks it where it lands
+	game.Press(ui.KeyLeft)
+	if game.activeTetro == resting {
+		t.Fatal("tetro still active after the 16th move on the stack")
+	}
+	if len(game.board.Blocks()) != 4 {
+		t.Errorf("board has %d blocks after the lock, want the tetro's 4", len(game.board.Blocks()))
+	}
+}
+
+func TestGame_LockDelayFallingResets(t *testing.T) {
+	game := newTestGame(t)
+	game.options.LockType = MoveResetLock
+
+	// use up all the move resets while resting on a ledge
+	ledge := game.boardHeight - 5
+	var blocks []tetris.Block
+	for _, block := range game.activeTetro.Ghost(game.board) {
+		x, _ := block.Coordinates()
+		blocks = append(blocks, tetris.NewBlock(x, ledge, "ledge"))
+	}
+	game.board.Place(blocks)
+	for game.activeTetro.CanMoveDown(game.board) {
+		game.moveTetroDown()
+	}
+	game.lockResets = maxLockResets
+	game.lockTimer = 10
+
+	// falling below the lowest row reached gives the tetro a fresh set of resets
+	game.board.ClearRow(ledge)
+	game.moveTetroDown()
+	if game.lockResets != 0 {
+		t.Errorf("lockResets = %d after falling to a new lowest row, want 0", game.lockResets)
+	}
+	if game.lockTimer != 0 {
+		t.Errorf("lockTimer = %d after falling to a new lowest row, want 0", game.lockTimer)
+	}
+}
+
+func TestParseLockType(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    LockType
+		expectError bool
+	}{
+		{"move", MoveResetLock, false},
+		{"step", StepResetLock, false},
+		{"infinite", InfiniteLock, false},
+		{"never", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockType, err := ParseLockType(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseLockType(%q) expected error, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseLockType(%q) unexpected error: %v", tt.name, err)
+			}
+			if lockType != tt.expected {
+				t.Errorf("ParseLockType(%q) = %v, want %v", tt.name, lockType, tt.expected)
+			}
+			if name := lockType.String(); name != tt.name {
+				t.Errorf("String() = %q, want %q", name, tt.name)
+			}
+		})
+	}
+}
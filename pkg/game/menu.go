@@ -0,0 +1,359 @@
+package game
+
+import (
+	"time"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+const (
+	lockDelayStep    = 50 * time.Millisecond // how much left and right change the lock delay in the options menu
+	maxMenuLockDelay = 2 * time.Second
+)
+
+const (
+	titleMenu menuScreen = iota
+	modeMenu
+	optionsMenu
+	highScoresMenu
+	controlsMenu
+	pauseMenu
+	quitMenu
+)
+
+// menuScreen is one screen of the menus
+type menuScreen int
+
+var menuTitles = map[menuScreen]string{
+	titleMenu:      "T E T R I S",
+	modeMenu:       "Choose a Mode",
+	optionsMenu:    "Options",
+	highScoresMenu: "High Scores",
+	controlsMenu:   "Controls",
+	pauseMenu:      "Paused",
+	quitMenu:       "Quit?",
+}
+
+// menu items, each is handled by its label
+const (
+	startItem      = "Start Game"
+	optionsItem    = "Options"
+	highScoresItem = "High Scores"
+	controlsItem   = "Controls"
+	quitItem       = "Quit"
+	marathonItem   = "Marathon"
+	sprintItem     = "Sprint"
+	ultraItem      = "Ultra"
+	digItem        = "Dig"
+	backItem       = "Back"
+	resumeItem     = "Resume"
+	restartItem    = "Restart"
+	yesItem        = "Yes"
+	noItem         = "No"
+	generatorItem  = "Generator"
+	scoringItem    = "Scoring"
+	gravityItem    = "Gravity"
+	lockItem       = "Lock"
+	lockDelayItem  = "Lock Delay"
+)
+
+var controls = []string{
+	"Left/Right - Move",
+	"Down       - Soft Drop",
+	"Space      - Hard Drop",
+	"Up         - Rotate",
+	"Z          - Rotate Left",
+	"A          - Rotate 180",
+	"C          - Hold",
+	"G          - Ghost",
+	"P          - Pause",
+	"R          - Restart, when paused or over",
+	"Esc        - Quit",
+}
+
+// choices for each setting in the options menu, in the order left and right step through them
+var (
+	generatorChoices = []tetris.GeneratorType{tetris.RandomGenerator, tetris.Bag7Generator, tetris.Bag14Generator, tetris.NESGenerator}
+	scoringChoices   = []ScoringType{GuidelineScoring, NESScoring}
+	gravityChoices   = []string{"guideline", "nes", "classic"}
+	lockChoices      = []LockType{MoveResetLock, StepResetLock, InfiniteLock}
+	modeChoices      = []ModeType{MarathonMode, SprintMode, UltraMode, DigMode} // modes in the order of the mode menu, and what left and right step through on the high score screen
+)
+
+// menu is the screen of the menus being shown and the item selected on it.
+// Going back returns to the previous menu, or to the game if there isn't one.
+type menu struct {
+	screen   menuScreen
+	selected int
+	previous *menu
+}
+
+// settings are the rules chosen in the options menu, for the next game started from the menus
+type settings struct {
+	generator tetris.GeneratorType
+	options   Options
+}
+
+// UseMenus plays the game through the menus, from the title screen unless it's a resumed game, with menus to pause
+// and quit and a way back to the title screen once the game is over. A game resumed while paused opens the pause menu.
+func (g *Game) UseMenus() {
+	g.menus = true
+	g.settings = settings{generator: g.generator.Type(), options: g.options}
+	if g.frames == 0 {
+		g.menu = &menu{screen: titleMenu}
+	} else if g.pause {
+		g.menu = &menu{screen: pauseMenu}
+	}
+}
+
+// openMenu opens a menu in place of the game's own handling of a key, returning false if the key is for the game
+func (g *Game) openMenu(keypress ui.KeyPress) bool {
+	switch {
+	case keypress == ui.KeyStop:
+		g.open(quitMenu)
+	case keypress == ui.KeyEnter && g.over && !g.naming:
+		g.menu = &menu{screen: titleMenu}
+	default:
+		return false
+	}
+	g.updateUI()
+	return true
+}
+
+// open shows a menu, going back from it returns to the menu shown now
+func (g *Game) open(screen menuScreen) {
+	g.menu = &menu{screen: screen, previous: g.menu}
+}
+
+// back returns to the previous menu, or to the game
+func (g *Game) back() {
+	if g.menu.screen == pauseMenu {
+		g.resume()
+		return
+	}
+	g.menu = g.menu.previous
+}
+
+// resume closes the pause menu and carries on with the game
+func (g *Game) resume() {
+	g.menu = nil
+	g.processKeyPress(ui.KeyPause)
+}
+
+// menuKey handles a key press while a menu is shown: up and down select an item, left and right change a setting,
+// or the mode on the high score screen, enter chooses the item and stop goes back
+func (g *Game) menuKey(keypress ui.KeyPress) {
+	items := g.menuItems()
+	selected := items[g.menu.selected].Label
+
+	switch keypress {
+	case ui.KeyUp:
+		g.menu.selected = (g.menu.selected + len(items) - 1) % len(items)
+	case ui.KeyDown:
+		g.menu.selected = (g.menu.selected + 1) % len(items)
+	case ui.KeyLeft, ui.KeyRight:
+		step := 1
+		if keypress == ui.KeyLeft {
+			step = -1
+		}
+		if g.menu.screen == highScoresMenu {
+			options := &g.settings.options
+			options.Mode = modeChoices[next(len(modeChoices), indexOf(modeChoices, options.Mode), step)]
+		} else {
+			g.changeSetting(selected, step)
+		}
+	case ui.KeyEnter:
+		g.choose(selected)
+	case ui.KeyPause:
+		if g.menu.screen == pauseMenu {
+			g.resume()
+		}
+	case ui.KeyRestart:
+		if g.menu.screen == pauseMenu {
+			g.restart()
+		}
+	case ui.KeyStop:
+		if g.menu.screen == titleMenu {
+			g.open(quitMenu)
+		} else {
+			g.back()
+		}
+	default:
+		return
+	}
+	g.updateUI()
+}
+
+// choose does what a menu item is for
+func (g *Game) choose(item string) {
+	switch item {
+	case startItem:
+		// the mode chosen last, or given on the command line, is selected to start with
+		g.open(modeMenu)
+		g.menu.selected = indexOf(modeChoices, g.settings.options.Mode)
+	case optionsItem:
+		g.open(optionsMenu)
+	case highScoresItem:
+		g.open(highScoresMenu)
+	case controlsItem:
+		g.open(controlsMenu)
+	case quitItem:
+		g.open(quitMenu)
+	case marathonItem:
+		g.settings.options.Mode = MarathonMode
+		g.begin()
+	case sprintItem:
+		g.settings.options.Mode = SprintMode
+		g.begin()
+	case ultraItem:
+		g.settings.options.Mode = UltraMode
+		g.begin()
+	case digItem:
+		g.settings.options.Mode = DigMode
+		g.begin()
+	case backItem, noItem:
+		g.back()
+	case resumeItem:
+		g.resume()
+	case restartItem:
+		g.restart()
+	case yesItem:
+		g.recordInput(ui.KeyStop)
+		g.stop()
+	default:
+		g.changeSetting(item, 1)
+	}
+}
+
+// changeSetting steps a setting in the options menu to its next or previous choice
+func (g *Game) changeSetting(item string, step int) {
+	options := &g.settings.options
+	switch item {
+	case generatorItem:
+		g.settings.generator = generatorChoices[next(len(generatorChoices), indexOf(generatorChoices, g.settings.generator), step)]
+	case scoringItem:
+		options.Scoring = scoringChoices[next(len(scoringChoices), indexOf(scoringChoices, options.Scoring), step)]
+	case gravityItem:
+		name, _ := GravityName(options.Gravity)
+		gravity, err := ParseGravity(gravityChoices[next(len(gravityChoices), indexOf(gravityChoices, name), step)])
+		if err != nil {
+			panic(err) // only the built in names are choices
+		}
+		options.Gravity = gravity
+	case lockItem:
+		options.LockType = lockChoices[next(len(lockChoices), indexOf(lockChoices, options.LockType), step)]
+	case lockDelayItem:
+		options.LockDelay += time.Duration(step) * lockDelayStep
+		if options.LockDelay < 0 {
+			options.LockDelay = 0
+		}
+		if options.LockDelay > maxMenuLockDelay {
+			options.LockDelay = maxMenuLockDelay
+		}
+	}
+}
+
+// next steps through n choices from index i, wrapping around at either end. A choice not in the list, e.g. custom
+// gravity given on the command line, has index -1 so it steps to the first or last choice.
+func next(n, i, step int) int {
+	if i < 0 && step < 0 {
+		return n - 1
+	}
+	return ((i+step)%n + n) % n
+}
+
+func indexOf[T comparable](choices []T, choice T) int {
+	for i, c := range choices {
+		if c == choice {
+			return i
+		}
+	}
+	return -1
+}
+
+// begin starts a game from the menus, with the rules chosen in the options menu.
+// A game that hasn't been played yet is played from its own seed, and carries on being recorded if it was.
+// Otherwise the game is played from the next seed, as with a restart.
+func (g *Game) begin() {
+	generator, err := tetris.NewGenerator(g.settings.generator)
+	if err != nil {
+		panic(err) // only the generators in the options menu are choices
+	}
+
+	seed, replay := g.seed, g.replay
+	if g.frames > 0 || g.over {
+		seed, replay = g.rng.Int63(), nil
+	}
+	g.replace(generator, seed, g.settings.options)
+
+	if replay != nil {
+		replay.Generator = generator.Type().String()
+		replay.Seed = g.seed
+		replay.Options = g.options
+		g.replay = replay
+	}
+}
+
+// menuItems lists the items on the menu being shown, with the settings chosen so far in the options menu
+func (g *Game) menuItems() []ui.MenuItem {
+	var labels []string
+	switch g.menu.screen {
+	case titleMenu:
+		labels = []string{startItem, optionsItem}
+		if g.highScores != nil {
+			labels = append(labels, highScoresItem)
+		}
+		labels = append(labels, controlsItem, quitItem)
+	case modeMenu:
+		labels = []string{marathonItem, sprintItem, ultraItem, digItem, backItem}
+	case optionsMenu:
+		gravity, err := GravityName(g.settings.options.Gravity)
+		if err != nil || indexOf(gravityChoices, gravity) < 0 {
+			gravity = "custom"
+		}
+		return []ui.MenuItem{
+			{Label: generatorItem, Value: g.settings.generator.String()},
+			{Label: scoringItem, Value: g.settings.options.Scoring.String()},
+			{Label: gravityItem, Value: gravity},
+			{Label: lockItem, Value: g.settings.options.LockType.String()},
+			{Label: lockDelayItem, Value: g.settings.options.LockDelay.String()},
+			{Label: backItem},
+		}
+	case highScoresMenu, controlsMenu:
+		labels = []string{backItem}
+	case pauseMenu:
+		labels = []string{resumeItem, restartItem, quitItem}
+	case quitMenu:
+		labels = []string{noItem, yesItem}
+	}
+
+	items := make([]ui.MenuItem, len(labels))
+	for i, label := range labels {
+		items[i] = ui.MenuItem{Label: label}
+	}
+	return items
+}
+
+// menuView is the menu for the UI to show, nil while playing or when the high score table is shown instead
+func (g *Game) menuView() *ui.Menu {
+	if g.menu == nil || g.menu.screen == highScoresMenu {
+		return nil
+	}
+
+	view := &ui.Menu{
+		Title:    menuTitles[g.menu.screen],
+		Items:    g.menuItems(),
+		Selected: g.menu.selected,
+	}
+	if g.menu.screen == controlsMenu {
+		view.Text = controls
+	}
+	// the menus reached from the title screen hide the game, which hasn't started yet
+	for m := g.menu; m != nil; m = m.previous {
+		if m.screen == titleMenu {
+			view.Cover = true
+		}
+	}
+	return view
+}
@@ -0,0 +1,243 @@
+package game
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// newMenuTestGame creates a test game played through the menus, on the title screen
+func newMenuTestGame(t *testing.T) (*Game, *ui.MockUI) {
+	game := newTestGame(t)
+	game.UseMenus()
+	game.updateUI()
+	return game, game.ui.(*ui.MockUI)
+}
+
+func press(game *Game, keys ...ui.KeyPress) {
+	for _, key := range keys {
+		game.Press(key)
+	}
+}
+
+// menuLabels lists the labels of a menu's items
+func menuLabels(menu *ui.Menu) []string {
+	var labels []string
+	for _, item := range menu.Items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestGame_TitleScreen(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+
+	if mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[titleMenu] || !mockUI.Menu.Cover {
+		t.Fatalf("menu shown = %+v, want the title screen over the game", mockUI.Menu)
+	}
+	if labels := menuLabels(mockUI.Menu); !reflect.DeepEqual(labels, []string{startItem, optionsItem, controlsItem, quitItem}) {
+		t.Errorf("title items = %v", labels)
+	}
+
+	game.Step(100)
+	if game.Frames() != 0 {
+		t.Errorf("game played %d frames on the title screen", game.Frames())
+	}
+
+	press(game, ui.KeyEnter, ui.KeyEnter) // start game, marathon
+	if mockUI.Menu != nil {
+		t.Errorf("menu shown = %+v after starting the game", mockUI.Menu)
+	}
+	if game.Seed() != 42 {
+		t.Errorf("game started from the title has seed %d, want the seed it was created with", game.Seed())
+	}
+	game.Step(10)
+	if game.Frames() != 10 {
+		t.Errorf("game played %d frames of 10 once started", game.Frames())
+	}
+}
+
+func TestGame_ModeMenuSelectsGameMode(t *testing.T) {
+	options := DefaultOptions()
+	options.Mode = SprintMode
+	game := newTestGameWith(t, options)
+	game.UseMenus()
+	mockUI := game.ui.(*ui.MockUI)
+
+	press(game, ui.KeyEnter) // start
+	if mockUI.Menu.Title != menuTitles[modeMenu] || mockUI.Menu.Items[mockUI.Menu.Selected].Label != sprintItem {
+		t.Fatalf("menu shown = %+v, want the modes with sprint selected", mockUI.Menu)
+	}
+	press(game, ui.KeyEnter)
+	if game.options.Mode != SprintMode {
+		t.Errorf("game started in mode %v, want sprint", game.options.Mode)
+	}
+}
+
+func TestGame_OptionsMenu(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+	replay := game.Record()
+
+	press(game, ui.KeyDown, ui.KeyEnter) // options
+	if mockUI.Menu.Title != menuTitles[optionsMenu] || mockUI.Menu.Items[0].Value != "7bag" {
+		t.Fatalf("menu shown = %+v, want the options with the game's generator", mockUI.Menu)
+	}
+
+	press(game,
+		ui.KeyRight,             // 14bag
+		ui.KeyDown, ui.KeyRight, // nes scoring
+		ui.KeyDown, ui.KeyLeft, // classic gravity, wrapping around from guideline
+		ui.KeyDown, ui.KeyEnter, // step reset lock
+		ui.KeyDown, ui.KeyLeft, // 450ms lock delay
+		ui.KeyDown, ui.KeyEnter, // back
+		ui.KeyUp, ui.KeyEnter, // start game
+		ui.KeyEnter, // marathon
+	)
+
+	if generatorType := game.generator.Type(); generatorType != tetris.Bag14Generator {
+		t.Errorf("generator = %v, want %v", generatorType, tetris.Bag14Generator)
+	}
+	gravity, _ := GravityName(game.options.Gravity)
+	if game.options.Scoring != NESScoring || gravity != "classic" || game.options.LockType != StepResetLock || game.options.LockDelay != 450*time.Millisecond {
+		t.Errorf("options = scoring %v, gravity %v, lock %v, delay %v, want the ones chosen", game.options.Scoring, gravity, game.options.LockType, game.options.LockDelay)
+	}
+
+	// the game hadn't been played, so it keeps its seed and recording
+	if game.Seed() != 42 || game.replay != replay {
+		t.Errorf("game started from the options has seed %d and replay %p, want 42 and %p", game.Seed(), game.replay, replay)
+	}
+	if replay.Generator != "14bag" || replay.Options.Scoring != NESScoring {
+		t.Errorf("replay has generator %q and scoring %v, want the ones chosen", replay.Generator, replay.Options.Scoring)
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		i, step int
+		want    int
+	}{
+		{0, 1, 1},
+		{2, 1, 0},
+		{0, -1, 2},
+		{-1, 1, 0},
+		{-1, -1, 2},
+	}
+
+	for _, tt := range tests {
+		if got := next(3, tt.i, tt.step); got != tt.want {
+			t.Errorf("next(3, %d, %d) = %d, want %d", tt.i, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestGame_PauseMenu(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+	replay := game.Record()
+	press(game, ui.KeyEnter, ui.KeyEnter)
+	game.Step(10)
+
+	game.Press(ui.KeyPause)
+	if !game.pause || mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[pauseMenu] || mockUI.Menu.Cover {
+		t.Fatalf("paused %v with menu %+v, want the pause menu over the game", game.pause, mockUI.Menu)
+	}
+
+	press(game, ui.KeyDown, ui.KeyUp, ui.KeyEnter) // resume
+	if game.pause || mockUI.Menu != nil {
+		t.Errorf("paused %v with menu %+v after resuming", game.pause, mockUI.Menu)
+	}
+
+	// only the pausing and resuming is recorded, not finding the way around the menu
+	want := []Input{{Frame: 10, Key: ui.KeyPause}, {Frame: 10, Key: ui.KeyPause}}
+	if !reflect.DeepEqual(replay.Inputs, want) {
+		t.Errorf("recorded inputs = %+v, want %+v", replay.Inputs, want)
+	}
+
+	game.Step(10)
+	press(game, ui.KeyPause, ui.KeyDown, ui.KeyEnter) // restart
+	if game.pause || game.Frames() != 0 || game.Seed() == 42 || mockUI.Menu != nil {
+		t.Errorf("restart from the pause menu left paused %v, frame %d, seed %d, menu %+v", game.pause, game.Frames(), game.Seed(), mockUI.Menu)
+	}
+	if !game.menus {
+		t.Error("restarted game isn't played through the menus")
+	}
+}
+
+func TestGame_QuitMenu(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+	press(game, ui.KeyEnter, ui.KeyEnter)
+	game.Step(10)
+
+	game.Press(ui.KeyStop)
+	if game.stopped || mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[quitMenu] {
+		t.Fatalf("stopped %v with menu %+v, want to be asked first", game.stopped, mockUI.Menu)
+	}
+	game.Press(ui.KeyEnter) // no
+	if game.stopped || mockUI.Menu != nil {
+		t.Errorf("stopped %v with menu %+v after choosing not to quit", game.stopped, mockUI.Menu)
+	}
+
+	// from the pause menu, saying no goes back to it
+	press(game, ui.KeyPause, ui.KeyDown, ui.KeyDown, ui.KeyEnter, ui.KeyStop)
+	if mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[pauseMenu] {
+		t.Errorf("menu shown = %+v, want the pause menu again", mockUI.Menu)
+	}
+
+	press(game, ui.KeyEnter, ui.KeyDown, ui.KeyEnter) // quit, yes
+	if !game.stopped || !mockUI.Stopped {
+		t.Errorf("game stopped %v, UI stopped %v, want both after choosing to quit", game.stopped, mockUI.Stopped)
+	}
+}
+
+func TestGame_MenusAfterGameOver(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+	scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	game.UseHighScores(scores)
+	press(game, ui.KeyEnter, ui.KeyEnter)
+	endTestGame(t, game)
+
+	game.Press(ui.KeyEnter) // save the high score
+	if help := game.overHelp(); mockUI.HighScores == nil || mockUI.HighScores.Help != help || mockUI.Mode.Help != help || !strings.Contains(help, "Title") {
+		t.Fatalf("keys shown once over = %q, want the way back to the title", help)
+	}
+	game.Press(ui.KeyEnter) // back to the title
+	if mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[titleMenu] {
+		t.Fatalf("menu shown = %+v, want the title screen", mockUI.Menu)
+	}
+
+	press(game, ui.KeyDown, ui.KeyDown, ui.KeyEnter) // high scores
+	if mockUI.Menu != nil || mockUI.HighScores == nil || len(mockUI.HighScores.Rows) != 1 || mockUI.HighScores.Help == "" {
+		t.Errorf("menu %+v and table %+v shown, want the high score table alone", mockUI.Menu, mockUI.HighScores)
+	}
+	game.Press(ui.KeyStop)
+	if mockUI.Menu == nil || mockUI.Menu.Title != menuTitles[titleMenu] || mockUI.HighScores != nil {
+		t.Errorf("menu %+v and table %+v shown, want the title screen again", mockUI.Menu, mockUI.HighScores)
+	}
+
+	press(game, ui.KeyUp, ui.KeyUp, ui.KeyEnter, ui.KeyEnter) // start game, marathon
+	if game.Over() || game.Seed() == 42 {
+		t.Errorf("new game from the title is over %v with seed %d, want a fresh game from a new seed", game.Over(), game.Seed())
+	}
+}
+
+func TestGame_QuitFromMenuEndsGameLoop(t *testing.T) {
+	game, mockUI := newMenuTestGame(t)
+	done := game.Start()
+
+	for _, key := range []ui.KeyPress{ui.KeyStop, ui.KeyDown, ui.KeyEnter} {
+		mockUI.SendKeyPress(key)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("game loop didn't finish after choosing to quit")
+	}
+}
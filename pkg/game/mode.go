@@ -0,0 +1,336 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+const (
+	MarathonMode ModeType = iota
+	SprintMode
+	UltraMode
+	DigMode
+)
+
+const (
+	defaultSprintLines  = 40              // the standard sprint
+	defaultUltraTime    = 2 * time.Minute // the standard ultra
+	defaultDigRows      = 10              // the standard dig race
+	defaultDigMessiness = 1               // every row's hole is in a different column to the one below
+	minDigSpace         = 4               // rows kept clear of garbage at the start of a dig race, for tetros to spawn in
+	digSeedMix          = 0x5DEECE66D     // mixed into the game's seed for the garbage holes, so they don't follow the tetros dealt
+)
+
+// ModeType is the goal a game is played to
+type ModeType int
+
+// modeNames maps the names used on the command line to each mode
+var modeNames = map[string]ModeType{
+	"marathon": MarathonMode,
+	"sprint":   SprintMode,
+	"ultra":    UltraMode,
+	"dig":      DigMode,
+}
+
+// ParseModeType returns the mode for a name such as "sprint"
+func ParseModeType(name string) (ModeType, error) {
+	modeType, ok := modeNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown mode: %q", name)
+	}
+	return modeType, nil
+}
+
+// String returns the name of the mode as used on the command line, e.g. "sprint"
+func (t ModeType) String() string {
+	for name, modeType := range modeNames {
+		if modeType == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("ModeType(%d)", int(t))
+}
+
+// better checks whether one game in the mode's high score table beats another:
+// the fastest time in a race, i.e. a sprint or dig race, otherwise the highest score
+func (t ModeType) better(a, b HighScore) bool {
+	if t == SprintMode || t == DigMode {
+		return a.Duration < b.Duration
+	}
+	return a.Score > b.Score
+}
+
+// mode is the goal of a game, and how the game is shown and ranked against that goal
+type mode interface {
+	// setup prepares the board before the first tetro is dealt
+	setup(g *Game)
+	// tick runs every frame the game is played, before checking whether it's finished
+	tick(g *Game)
+	// finished checks whether the goal has been reached, each frame and once a tetro has locked and its lines have been counted
+	finished(g *Game) bool
+	// status is what the UI shows beside the board
+	status(g *Game) ui.ModeStatus
+	// ranked checks whether the mode's settings and how the game ended let it into the high score table, see Game.ranked
+	ranked(g *Game) bool
+	// results are shown once the game is over, nil for a plain game over
+	results(g *Game) []string
+}
+
+// newMode creates the game's mode. Garbage holes are random, so the dig race has its own random numbers, seeded from
+// the game's seed mixed with digSeedMix so they're apart from those used to deal tetros.
+func newMode(options Options, seed int64) (mode, error) {
+	switch options.Mode {
+	case MarathonMode:
+		return marathon{}, nil
+	case SprintMode:
+		return sprint{lines: options.SprintLines}, nil
+	case UltraMode:
+		return ultra{limit: options.UltraTime}, nil
+	case DigMode:
+		return &dig{
+			rows:      options.DigRows,
+			messiness: options.DigMessiness,
+			rise:      framesIn(options.DigRise),
+			rng:       rand.New(rand.NewSource(seed ^ digSeedMix)),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mode: %d", options.Mode)
+	}
+}
+
+// elapsed is the time played so far, by the game's own clock
+func (g *Game) elapsed() time.Duration {
+	return time.Duration(g.frames) * frameDuration
+}
+
+// still is embedded by the modes that play on the board as it is, with nothing added to it
+type still struct{}
+
+func (still) setup(g *Game) {}
+
+func (still) tick(g *Game) {}
+
+// marathon is the classic game, played for score until the stack reaches the top
+type marathon struct {
+	still
+}
+
+func (marathon) finished(g *Game) bool {
+	return false
+}
+
+func (marathon) status(g *Game) ui.ModeStatus {
+	return ui.ModeStatus{Name: "Marathon", Timer: g.elapsed()}
+}
+
+func (marathon) ranked(g *Game) bool {
+	return true
+}
+
+func (marathon) results(g *Game) []string {
+	return nil
+}
+
+// sprint is a race to clear a number of lines, 40 as standard, as fast as possible
+type sprint struct {
+	still
+	lines int
+}
+
+func (s sprint) finished(g *Game) bool {
+	return g.cleared >= s.lines
+}
+
+func (s sprint) status(g *Game) ui.ModeStatus {
+	cleared := g.cleared
+	if cleared > s.lines {
+		cleared = s.lines
+	}
+	return ui.ModeStatus{
+		Name:  "Sprint",
+		Timer: g.elapsed(),
+		Goal:  fmt.Sprintf("%d/%d lines", cleared, s.lines),
+	}
+}
+
+// ranked only lets a finished sprint of the standard distance into the table, so every time in it is for the same race
+func (s sprint) ranked(g *Game) bool {
+	return g.completed && s.lines == defaultSprintLines
+}
+
+// results are the time taken, with pieces per second and keys per piece, and how it compares to the personal best
+func (s sprint) results(g *Game) []string {
+	if !g.completed {
+		return []string{"Game Over", fmt.Sprintf("%d/%d lines", g.cleared, s.lines)}
+	}
+
+	elapsed := g.elapsed()
+	results := []string{
+		"Sprint Complete",
+		"",
+		"Time:  " + ui.FormatDuration(elapsed),
+		fmt.Sprintf("PPS:   %.2f", float64(g.pieces)/elapsed.Seconds()),
+		fmt.Sprintf("KPP:   %.2f", float64(g.keys)/float64(g.pieces)),
+	}
+
+	if g.ranked() {
+		results = g.personalBest(results, func(best HighScore) string { return ui.FormatDuration(best.Duration) })
+	}
+	return results
+}
+
+// ultra is a race for the highest score in a time limit, 2 minutes as standard
+type ultra struct {
+	still
+	limit time.Duration
+}
+
+// finished counts the time limit in frames, like the rest of the game's timers
+func (u ultra) finished(g *Game) bool {
+	return g.frames >= framesIn(u.limit)
+}
+
+// status counts down the time left
+func (u ultra) status(g *Game) ui.ModeStatus {
+	left := framesIn(u.limit) - g.frames
+	if left < 0 {
+		left = 0
+	}
+	return ui.ModeStatus{Name: "Ultra", Timer: time.Duration(left) * frameDuration}
+}
+
+// ranked only lets a game played to the end of the standard time limit into the table, so every score in it had the same time
+func (u ultra) ranked(g *Game) bool {
+	return g.completed && u.limit == defaultUltraTime
+}
+
+// results are the score and lines cleared in the time, and how the score compares to the personal best
+func (u ultra) results(g *Game) []string {
+	title := "Time's Up"
+	if !g.completed {
+		title = "Game Over"
+	}
+	results := []string{
+		title,
+		"",
+		fmt.Sprintf("Score: %d", g.score),
+		fmt.Sprintf("Lines: %d", g.cleared),
+	}
+	if g.ranked() {
+		results = g.personalBest(results, func(best HighScore) string { return fmt.Sprint(best.Score) })
+	}
+	return results
+}
+
+// dig is a race to clear rows of garbage from the bottom of the board, each with a single hole to dig down through.
+// Messiness is the chance each row's hole is in a different column to the one below it, from 0 for a single well
+// to 1 for a new column every row. Garbage can also rise from the bottom as the race goes on.
+type dig struct {
+	rows      int
+	messiness float64
+	rise      int        // frames between rows of garbage rising, 0 if it doesn't
+	rng       *rand.Rand // chooses the holes
+	hole      int        // column of the hole in the last row of garbage made
+	made      int        // rows of garbage made so far, so a saved game can make them again to get back to the same holes
+	riseTimer int        // frames since garbage last rose
+}
+
+func (d *dig) setup(g *Game) {
+	for i := 0; i < d.rows; i++ {
+		g.addGarbage(d.nextHole(g.boardWidth))
+	}
+}
+
+// tick raises a row of garbage every so often. It waits while cleared rows are removed, as the stack is about to move.
+func (d *dig) tick(g *Game) {
+	if d.rise == 0 {
+		return
+	}
+	if d.riseTimer < d.rise {
+		d.riseTimer++
+	}
+	if d.riseTimer < d.rise || g.clearTime > 0 {
+		return
+	}
+	d.riseTimer = 0
+	if !g.addGarbage(d.nextHole(g.boardWidth)) {
+		g.end() // the stack was pushed off the top
+	}
+}
+
+// nextHole chooses the column of the hole in the next row of garbage
+func (d *dig) nextHole(width int) int {
+	switch {
+	case d.made == 0:
+		d.hole = d.rng.Intn(width)
+	case d.rng.Float64() < d.messiness:
+		d.hole = (d.hole + 1 + d.rng.Intn(width-1)) % width // any column but the last one
+	}
+	d.made++
+	return d.hole
+}
+
+// remake makes the same rows of garbage again, without adding them to the board, to bring the holes and random
+// numbers back to where they were in a saved game
+func (d *dig) remake(made, width int) {
+	for d.made < made {
+		d.nextHole(width)
+	}
+}
+
+func (d *dig) finished(g *Game) bool {
+	return g.garbage == 0
+}
+
+func (d *dig) status(g *Game) ui.ModeStatus {
+	return ui.ModeStatus{
+		Name:  "Dig",
+		Timer: g.elapsed(),
+		Goal:  fmt.Sprintf("%d garbage left", g.garbage),
+	}
+}
+
+// ranked only lets a finished race of the standard garbage into the table, so every time in it is for the same race
+func (d *dig) ranked(g *Game) bool {
+	return g.completed && d.rows == defaultDigRows && d.messiness == defaultDigMessiness && d.rise == 0
+}
+
+// results are the time taken and the pieces used, and how the time compares to the personal best
+func (d *dig) results(g *Game) []string {
+	if !g.completed {
+		return []string{"Game Over", fmt.Sprintf("%d garbage left", g.garbage)}
+	}
+
+	results := []string{
+		"Dig Complete",
+		"",
+		"Time:   " + ui.FormatDuration(g.elapsed()),
+		fmt.Sprintf("Pieces: %d", g.pieces),
+	}
+	if g.ranked() {
+		results = g.personalBest(results, func(best HighScore) string { return ui.FormatDuration(best.Duration) })
+	}
+	return results
+}
+
+// ranked checks whether the game can go in its mode's high score table once it's over. Only games played by the
+// default rules are ranked, and the mode decides which of those are.
+func (g *Game) ranked() bool {
+	return g.options.standardRules() && g.mode.ranked(g)
+}
+
+// personalBest adds how the game compares to the best in its mode's high score table to its results,
+// describing the best game with describe
+func (g *Game) personalBest(results []string, describe func(best HighScore) string) []string {
+	if g.highScores == nil {
+		return results
+	}
+	top := g.highScores.Top(g.options.Mode)
+	if len(top) == 0 || g.options.Mode.better(g.highScore(), top[0]) {
+		return append(results, "New personal best!")
+	}
+	return append(results, "Best:  "+describe(top[0]))
+}
@@ -0,0 +1,401 @@
+package game
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// newSprintTestGame creates a test game of a sprint to the given number of lines
+func newSprintTestGame(t *testing.T, lines int) (*Game, *ui.MockUI) {
+	options := DefaultOptions()
+	options.Mode = SprintMode
+	options.SprintLines = lines
+	game := newTestGameWith(t, options)
+	return game, game.ui.(*ui.MockUI)
+}
+
+// fillUnderTetro fills the bottom row, apart from where the active tetro will land, so dropping it clears a line
+func fillUnderTetro(game *Game) {
+	bottom := game.boardHeight - 1
+	landing := map[int]bool{}
+	for _, block := range game.activeTetro.Ghost(game.board) {
+		x, y := block.Coordinates()
+		if y == bottom {
+			landing[x] = true
+		}
+	}
+	var blocks []tetris.Block
+	for x := 0; x < game.boardWidth; x++ {
+		if !landing[x] {
+			blocks = append(blocks, tetris.NewBlock(x, bottom, "fill"))
+		}
+	}
+	game.board.Place(blocks)
+}
+
+func TestParseModeType(t *testing.T) {
+	tests := []struct {
+		name        string
+		want        ModeType
+		expectError bool
+	}{
+		{"marathon", MarathonMode, false},
+		{"sprint", SprintMode, false},
+		{"ultra", UltraMode, false},
+		{"zen", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseModeType(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseModeType(%q) expected error, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseModeType(%q) unexpected error: %v", tt.name, err)
+			}
+			if got != tt.want || got.String() != tt.name {
+				t.Errorf("ParseModeType(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGame_Sprint(t *testing.T) {
+	game, mockUI := newSprintTestGame(t, 1)
+
+	game.Step(30)
+	if mockUI.Mode.Name != "Sprint" || mockUI.Mode.Goal != "0/1 lines" || mockUI.Mode.Timer != 30*frameDuration {
+		t.Errorf("mode shown = %+v, want the sprint's progress and the time played", mockUI.Mode)
+	}
+
+	press(game, ui.KeyLeft, ui.KeyRight)
+	fillUnderTetro(game)
+	game.Press(ui.KeyHardDrop)
+
+	if !game.Over() || !game.completed {
+		t.Fatalf("game over %v, completed %v after clearing the sprint's lines, want both", game.Over(), game.completed)
+	}
+	want := []string{"Sprint Complete", "", "Time:  0:00.500", "PPS:   2.00", "KPP:   3.00"}
+	if !reflect.DeepEqual(mockUI.Mode.Results, want) {
+		t.Errorf("results shown = %q, want %q", mockUI.Mode.Results, want)
+	}
+
+	// the timer stops once the sprint is finished
+	game.Step(30)
+	if mockUI.Mode.Timer != 30*frameDuration {
+		t.Errorf("timer = %v after the sprint finished, want %v", mockUI.Mode.Timer, 30*frameDuration)
+	}
+}
+
+func TestGame_SprintPersonalBest(t *testing.T) {
+	tests := []struct {
+		name   string
+		best   time.Duration
+		naming bool
+		want   string
+	}{
+		{"no time yet", 0, true, "New personal best!"},
+		{"faster", time.Minute, true, "New personal best!"},
+		{"slower", time.Second / 10, true, "Best:  0:00.100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, mockUI := newSprintTestGame(t, defaultSprintLines)
+			scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+			if err != nil {
+				t.Fatalf("LoadHighScores() unexpected error: %v", err)
+			}
+			if tt.best > 0 {
+				scores.Add(SprintMode, HighScore{Name: "OLD", Duration: tt.best})
+			}
+			game.UseHighScores(scores)
+
+			game.cleared = defaultSprintLines - 1
+			game.Step(30)
+			fillUnderTetro(game)
+			game.Press(ui.KeyHardDrop)
+
+			if game.naming != tt.naming {
+				t.Errorf("naming = %v, want %v", game.naming, tt.naming)
+			}
+			results := mockUI.Mode.Results
+			if len(results) == 0 || results[len(results)-1] != tt.want {
+				t.Errorf("results shown = %q, want them to end with %q", results, tt.want)
+			}
+			if mockUI.HighScores == nil || !reflect.DeepEqual(mockUI.HighScores.Text, results) {
+				t.Errorf("high score table = %+v, want the results above it", mockUI.HighScores)
+			}
+
+			game.Press(ui.KeyEnter)
+			top := scores.Top(SprintMode)
+			if top[0].Duration > 30*frameDuration {
+				t.Errorf("fastest time = %v, want the sprint's %v or faster", top[0].Duration, 30*frameDuration)
+			}
+		})
+	}
+}
+
+func TestGame_SprintToppedOut(t *testing.T) {
+	game, mockUI := newSprintTestGame(t, defaultSprintLines)
+	scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	game.UseHighScores(scores)
+
+	endTestGame(t, game)
+	if game.completed || game.naming {
+		t.Errorf("completed %v, naming %v after topping out, want neither", game.completed, game.naming)
+	}
+	if want := []string{"Game Over", "0/40 lines"}; !reflect.DeepEqual(mockUI.Mode.Results, want) {
+		t.Errorf("results shown = %q, want %q", mockUI.Mode.Results, want)
+	}
+}
+
+func TestHighScores_RankSprint(t *testing.T) {
+	scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	scores.Add(SprintMode, HighScore{Name: "MID", Duration: time.Minute, Score: 100})
+	scores.Add(SprintMode, HighScore{Name: "SLO", Duration: 2 * time.Minute, Score: 900})
+	scores.Add(SprintMode, HighScore{Name: "FST", Duration: 30 * time.Second})
+
+	var names []string
+	for _, score := range scores.Top(SprintMode) {
+		names = append(names, score.Name)
+	}
+	if want := []string{"FST", "MID", "SLO"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sprint table = %v, want fastest first %v", names, want)
+	}
+	if len(scores.Top(MarathonMode)) != 0 {
+		t.Errorf("marathon table = %+v, want it kept apart from the sprint's", scores.Top(MarathonMode))
+	}
+}
+
+func TestGame_Ultra(t *testing.T) {
+	options := DefaultOptions()
+	options.Mode = UltraMode
+	options.UltraTime = time.Second
+	game := newTestGameWith(t, options)
+	mockUI := game.ui.(*ui.MockUI)
+
+	game.Step(frameRate - 1)
+	if game.Over() || mockUI.Mode.Name != "Ultra" || mockUI.Mode.Timer != frameDuration {
+		t.Fatalf("over %v with mode shown %+v a frame before time's up, want the last frame counting down", game.Over(), mockUI.Mode)
+	}
+
+	game.Step(1)
+	if !game.Over() || !game.completed || mockUI.Mode.Timer != 0 {
+		t.Fatalf("over %v, completed %v, timer %v once time's up, want the game finished", game.Over(), game.completed, mockUI.Mode.Timer)
+	}
+	if want := []string{"Time's Up", "", "Score: 0", "Lines: 0"}; !reflect.DeepEqual(mockUI.Mode.Results, want) {
+		t.Errorf("results shown = %q, want %q", mockUI.Mode.Results, want)
+	}
+
+	game.Step(10)
+	if game.Frames() != frameRate {
+		t.Errorf("game played %d frames, want it to stop at the time limit of %d", game.Frames(), frameRate)
+	}
+}
+
+func TestGame_UltraHighScore(t *testing.T) {
+	options := DefaultOptions()
+	options.Mode = UltraMode
+	game := newTestGameWith(t, options)
+	mockUI := game.ui.(*ui.MockUI)
+	scores, err := LoadHighScores(filepath.Join(t.TempDir(), "highscores.json"))
+	if err != nil {
+		t.Fatalf("LoadHighScores() unexpected error: %v", err)
+	}
+	game.UseHighScores(scores)
+
+	game.frames = framesIn(defaultUltraTime) - 1 // left alone, the stack would reach the top before time's up
+	game.Step(1)
+	if !game.Over() || !game.naming {
+		t.Fatalf("over %v, naming %v at the end of a standard ultra, want its score entered", game.Over(), game.naming)
+	}
+	if results := mockUI.Mode.Results; results[len(results)-1] != "New personal best!" {
+		t.Errorf("results shown = %q, want a new personal best", results)
+	}
+
+	game.Press(ui.KeyEnter)
+	if top := scores.Top(UltraMode); len(top) != 1 || top[0].Duration != time.Duration(framesIn(defaultUltraTime))*frameDuration {
+		t.Errorf("ultra table = %+v, want the game played for the whole time", top)
+	}
+}
+
+// newDigTestGame creates a test game of a dig race with the given garbage
+func newDigTestGame(t *testing.T, rows int, messiness float64, rise time.Duration) (*Game, *ui.MockUI) {
+	options := DefaultOptions()
+	options.Mode = DigMode
+	options.DigRows = rows
+	options.DigMessiness = messiness
+	options.DigRise = rise
+	game := newTestGameWith(t, options)
+	return game, game.ui.(*ui.MockUI)
+}
+
+// garbageHoles returns the column of the hole in each row of garbage, from the top row of garbage down
+func garbageHoles(t *testing.T, game *Game) []int {
+	var holes []int
+	for y := game.boardHeight - game.garbage; y < game.boardHeight; y++ {
+		hole := -1
+		for x := 0; x < game.boardWidth; x++ {
+			if game.board.Free(x, y) {
+				if hole >= 0 {
+					t.Fatalf("row %d of garbage has holes at %d and %d, want one", y, hole, x)
+				}
+				hole = x
+			}
+		}
+		if hole < 0 {
+			t.Fatalf("row %d of garbage has no hole", y)
+		}
+		holes = append(holes, hole)
+	}
+	return holes
+}
+
+func TestGame_DigSetup(t *testing.T) {
+	tests := []struct {
+		name      string
+		messiness float64
+		same      bool // every hole in the same column
+	}{
+		{"clean", 0, true},
+		{"messy", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, mockUI := newDigTestGame(t, 8, tt.messiness, 0)
+			game.updateUI()
+
+			if game.garbage != 8 || mockUI.Mode.Goal != "8 garbage left" {
+				t.Fatalf("garbage = %d, goal shown %q, want 8 rows", game.garbage, mockUI.Mode.Goal)
+			}
+			for y := 0; y < game.boardHeight-game.garbage; y++ {
+				if !game.board.RowEmpty(y) {
+					t.Errorf("row %d above the garbage isn't empty", y)
+				}
+			}
+
+			holes := garbageHoles(t, game)
+			for i := 1; i < len(holes); i++ {
+				if same := holes[i] == holes[i-1]; same != tt.same {
+					t.Errorf("holes = %v, want each in the same column as the one below %v", holes, tt.same)
+					break
+				}
+			}
+			if block := game.board.Blocks()[0]; block.Style() != "garbage" {
+				t.Errorf("garbage block style = %v, want the UI's garbage style", block.Style())
+			}
+		})
+	}
+}
+
+func TestNewMode_DigSeed(t *testing.T) {
+	options := DefaultOptions()
+	options.Mode = DigMode
+	m, err := newMode(options, 42)
+	if err != nil {
+		t.Fatalf("newMode() unexpected error: %v", err)
+	}
+
+	// the holes come from their own random numbers, not the ones the game deals tetros from
+	dealt := rand.New(rand.NewSource(42))
+	for i := 0; i < 10; i++ {
+		if hole, tetro := m.(*dig).rng.Int63(), dealt.Int63(); hole == tetro {
+			t.Fatalf("dig random number %d = %d, the same as the game's", i, hole)
+		}
+	}
+}
+
+func TestGame_DigComplete(t *testing.T) {
+	game, mockUI := newDigTestGame(t, 1, 1, 0)
+
+	// fill the hole, the row clears once the next tetro locks
+	bottom := game.boardHeight - 1
+	game.board.Place([]tetris.Block{tetris.NewBlock(garbageHoles(t, game)[0], bottom, "fill")})
+	game.Step(30)
+	game.Press(ui.KeyHardDrop)
+
+	if game.garbage != 0 || !game.Over() || !game.completed {
+		t.Fatalf("garbage %d, over %v, completed %v once the garbage is cleared, want the race finished", game.garbage, game.Over(), game.completed)
+	}
+	want := []string{"Dig Complete", "", "Time:   0:00.500", "Pieces: 1"}
+	if !reflect.DeepEqual(mockUI.Mode.Results, want) {
+		t.Errorf("results shown = %q, want %q", mockUI.Mode.Results, want)
+	}
+}
+
+func TestGame_DigRise(t *testing.T) {
+	game, _ := newDigTestGame(t, 2, 1, time.Second)
+
+	// rest the active tetro on the garbage, where the next row pushes it up
+	for game.activeTetro.CanMoveDown(game.board) {
+		game.activeTetro.MoveDown()
+	}
+	lowest := lowestRow(game.activeTetro)
+	game.mode.(*dig).riseTimer = framesIn(time.Second) - 1
+
+	game.Step(1)
+	if game.garbage != 3 {
+		t.Fatalf("garbage = %d after a second, want a row to have risen", game.garbage)
+	}
+	garbageHoles(t, game)
+	if !game.activeTetro.Fits(game.board) || lowestRow(game.activeTetro) != lowest-1 {
+		t.Errorf("active tetro's lowest row = %d, fits %v, want pushed up to %d", lowestRow(game.activeTetro), game.activeTetro.Fits(game.board), lowest-1)
+	}
+
+	// rising garbage waits while cleared rows are removed
+	game.clearTime = 2
+	game.mode.(*dig).riseTimer = framesIn(time.Second) - 1
+	game.Step(1)
+	if game.garbage != 3 {
+		t.Errorf("garbage = %d, want it to wait for cleared rows", game.garbage)
+	}
+}
+
+func TestGame_DigToppedOut(t *testing.T) {
+	game, mockUI := newDigTestGame(t, DefaultOptions().BoardHeight-minDigSpace, 1, time.Second/10)
+
+	game.Step(framesIn(5 * time.Second))
+	if !game.Over() || game.completed {
+		t.Fatalf("over %v, completed %v with garbage rising to the top, want the game lost", game.Over(), game.completed)
+	}
+	if mockUI.Mode.Results[0] != "Game Over" {
+		t.Errorf("results shown = %q, want game over", mockUI.Mode.Results)
+	}
+}
+
+func TestGame_DigSaveAndResume(t *testing.T) {
+	game, _ := newDigTestGame(t, 6, 0.5, time.Second)
+	playTestGame(game, 5)
+
+	resumed := saveAndResume(t, game)
+	original, _ := game.Save()
+	again, _ := resumed.Save()
+	if !reflect.DeepEqual(again, original) {
+		t.Fatalf("resumed game saves as %+v, want %+v", again, original)
+	}
+
+	// the same garbage rises in both games from here
+	game.Step(framesIn(3 * time.Second))
+	resumed.Step(framesIn(3 * time.Second))
+	if !reflect.DeepEqual(resumed.board.Blocks(), game.board.Blocks()) || resumed.garbage != game.garbage {
+		t.Error("resumed game's garbage differs from the original")
+	}
+}
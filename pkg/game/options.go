@@ -0,0 +1,243 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+)
+
+// CentreSpawn spawns tetros in the middle of the board, whatever its width
+const CentreSpawn = -1
+
+const (
+	minBoardSize = 4 // the I shape lies 4 blocks wide and stands 4 tall
+	maxBoardSize = 50
+	maxQueueSize = 7
+)
+
+// Options are the rules a game is played with
+type Options struct {
+	Scoring      ScoringType
+	Gravity      Gravity
+	LockType     LockType
+	LockDelay    time.Duration // how long a tetro can rest on the stack before it locks in place
+	BoardHeight  int
+	BoardWidth   int
+	SpawnX       int // column tetros spawn in, or CentreSpawn
+	SpawnY       int // row tetros spawn in
+	QueueSize    int // tetros shown coming up next
+	Mode         ModeType
+	SprintLines  int           // lines to clear to finish a sprint
+	UltraTime    time.Duration // time limit of an ultra
+	DigRows      int           // rows of garbage at the start of a dig race
+	DigMessiness float64       // chance, from 0 to 1, that each row of garbage has its hole in a different column to the one below
+	DigRise      time.Duration // how often a row of garbage rises from the bottom in a dig race, 0 if it doesn't
+}
+
+// DefaultOptions returns the options for a standard game
+func DefaultOptions() Options {
+	return Options{
+		Scoring:      GuidelineScoring,
+		Gravity:      guidelineGravity{},
+		LockType:     MoveResetLock,
+		LockDelay:    500 * time.Millisecond,
+		BoardHeight:  20,
+		BoardWidth:   10,
+		SpawnX:       CentreSpawn,
+		SpawnY:       0,
+		QueueSize:    5,
+		Mode:         MarathonMode,
+		SprintLines:  defaultSprintLines,
+		UltraTime:    defaultUltraTime,
+		DigRows:      defaultDigRows,
+		DigMessiness: defaultDigMessiness,
+	}
+}
+
+// Validate checks the options can be played with
+func (o Options) Validate() error {
+	if o.Scoring < GuidelineScoring || o.Scoring > NESScoring {
+		return fmt.Errorf("unsupported scoring type: %d", o.Scoring)
+	}
+	if o.Gravity == nil {
+		return fmt.Errorf("gravity is not set")
+	}
+	if o.LockType < MoveResetLock || o.LockType > InfiniteLock {
+		return fmt.Errorf("unsupported lock type: %d", o.LockType)
+	}
+	if o.LockDelay < 0 {
+		return fmt.Errorf("lock delay can't be negative: %v", o.LockDelay)
+	}
+	if o.BoardHeight < minBoardSize || o.BoardHeight > maxBoardSize || o.BoardWidth < minBoardSize || o.BoardWidth > maxBoardSize {
+		return fmt.Errorf("board must be from %d to %d blocks high and wide: %dx%d", minBoardSize, maxBoardSize, o.BoardWidth, o.BoardHeight)
+	}
+	if err := o.validateSpawn(); err != nil {
+		return err
+	}
+	if o.QueueSize < 1 || o.QueueSize > maxQueueSize {
+		return fmt.Errorf("queue must show from 1 to %d tetros: %d", maxQueueSize, o.QueueSize)
+	}
+	if o.Mode < MarathonMode || o.Mode > DigMode {
+		return fmt.Errorf("unsupported mode: %d", o.Mode)
+	}
+	if o.Mode == SprintMode && o.SprintLines <= 0 {
+		return fmt.Errorf("sprint must have lines to clear: %d", o.SprintLines)
+	}
+	if o.Mode == UltraMode && o.UltraTime <= 0 {
+		return fmt.Errorf("ultra must have a time limit: %v", o.UltraTime)
+	}
+	if o.Mode == DigMode {
+		if o.DigRows <= 0 || o.DigRows > o.BoardHeight-minDigSpace {
+			return fmt.Errorf("dig race must have from 1 to %d rows of garbage: %d", o.BoardHeight-minDigSpace, o.DigRows)
+		}
+		if o.DigMessiness < 0 || o.DigMessiness > 1 {
+			return fmt.Errorf("garbage messiness must be from 0 to 1: %v", o.DigMessiness)
+		}
+		if o.DigRise < 0 {
+			return fmt.Errorf("garbage can't rise every %v", o.DigRise)
+		}
+	}
+	return nil
+}
+
+// spawnX is the column tetros spawn in, working out the middle of the board for CentreSpawn.
+// The I shape reaches 2 columns right of the spawn column, so on the narrowest board it spawns a column further left.
+func (o Options) spawnX() int {
+	if o.SpawnX != CentreSpawn {
+		return o.SpawnX
+	}
+	if centre := o.BoardWidth / 2; centre < o.BoardWidth-2 {
+		return centre
+	}
+	return o.BoardWidth - 3
+}
+
+// standardRules checks the game is played by the default rules on the standard board, so it can be ranked against other games
+func (o Options) standardRules() bool {
+	standard := DefaultOptions()
+	gravity, _ := GravityName(o.Gravity)
+	standardGravity, _ := GravityName(standard.Gravity)
+	return o.Scoring == standard.Scoring && gravity == standardGravity && o.LockType == standard.LockType &&
+		o.LockDelay == standard.LockDelay && o.BoardHeight == standard.BoardHeight && o.BoardWidth == standard.BoardWidth &&
+		o.spawnX() == standard.spawnX() && o.SpawnY == standard.SpawnY && o.QueueSize == standard.QueueSize
+}
+
+// validateSpawn checks every shape spawns between the walls and above the floor, no higher than the top of the buffer.
+// Tetros can spawn partly or wholly in the buffer above the board.
+func (o Options) validateSpawn() error {
+	factory := tetris.NewFactory(tetris.Config{SpawnX: o.spawnX(), SpawnY: o.SpawnY})
+	for shape := tetris.ShapeO; shape <= tetris.ShapeT; shape++ {
+		tetro, err := factory.NewTetro(shape)
+		if err != nil {
+			return err
+		}
+		for _, block := range tetro.Blocks() {
+			if x, y := block.Coordinates(); x < 0 || x >= o.BoardWidth || y < -bufferRows || y >= o.BoardHeight {
+				return fmt.Errorf("spawn position (%d,%d) puts the %v shape outside the board", o.spawnX(), o.SpawnY, shape)
+			}
+		}
+	}
+	return nil
+}
+
+// optionsJSON is how options are saved, by the same names used on the command line
+type optionsJSON struct {
+	Scoring     string   `json:"scoring"`
+	Gravity     string   `json:"gravity"`
+	LockType    string   `json:"lock"`
+	LockDelay   string   `json:"lockDelay"`
+	BoardHeight int      `json:"boardHeight"`
+	BoardWidth  int      `json:"boardWidth"`
+	SpawnX      int      `json:"spawnX"`
+	SpawnY      int      `json:"spawnY"`
+	QueueSize   int      `json:"queueSize"`
+	Mode        string   `json:"mode"`
+	SprintLines int      `json:"sprintLines,omitempty"` // only saved for a sprint
+	UltraTime   string   `json:"ultraTime,omitempty"`   // only saved for an ultra
+	Dig         *digJSON `json:"dig,omitempty"`         // only saved for a dig race
+}
+
+// digJSON is how the garbage of a dig race is saved, a messiness of 0 can't be left out as the default is 1
+type digJSON struct {
+	Rows      int     `json:"rows"`
+	Messiness float64 `json:"messiness"`
+	Rise      string  `json:"rise"`
+}
+
+func (o Options) MarshalJSON() ([]byte, error) {
+	gravity, err := GravityName(o.Gravity)
+	if err != nil {
+		return nil, err
+	}
+	saved := optionsJSON{
+		Scoring:     o.Scoring.String(),
+		Gravity:     gravity,
+		LockType:    o.LockType.String(),
+		LockDelay:   o.LockDelay.String(),
+		BoardHeight: o.BoardHeight,
+		BoardWidth:  o.BoardWidth,
+		SpawnX:      o.SpawnX,
+		SpawnY:      o.SpawnY,
+		QueueSize:   o.QueueSize,
+		Mode:        o.Mode.String(),
+	}
+	switch o.Mode {
+	case SprintMode:
+		saved.SprintLines = o.SprintLines
+	case UltraMode:
+		saved.UltraTime = o.UltraTime.String()
+	case DigMode:
+		saved.Dig = &digJSON{Rows: o.DigRows, Messiness: o.DigMessiness, Rise: o.DigRise.String()}
+	}
+	return json.Marshal(saved)
+}
+
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var saved optionsJSON
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	options := DefaultOptions() // settings of the other modes, which aren't saved
+
+	var err error
+	if options.Scoring, err = ParseScoringType(saved.Scoring); err != nil {
+		return err
+	}
+	if options.Gravity, err = ParseGravity(saved.Gravity); err != nil {
+		return err
+	}
+	if options.LockType, err = ParseLockType(saved.LockType); err != nil {
+		return err
+	}
+	if options.LockDelay, err = time.ParseDuration(saved.LockDelay); err != nil {
+		return err
+	}
+	options.BoardHeight = saved.BoardHeight
+	options.BoardWidth = saved.BoardWidth
+	options.SpawnX = saved.SpawnX
+	options.SpawnY = saved.SpawnY
+	options.QueueSize = saved.QueueSize
+	if options.Mode, err = ParseModeType(saved.Mode); err != nil {
+		return err
+	}
+	if saved.SprintLines != 0 {
+		options.SprintLines = saved.SprintLines
+	}
+	if saved.UltraTime != "" {
+		if options.UltraTime, err = time.ParseDuration(saved.UltraTime); err != nil {
+			return err
+		}
+	}
+	if saved.Dig != nil {
+		options.DigRows = saved.Dig.Rows
+		options.DigMessiness = saved.Dig.Messiness
+		if options.DigRise, err = time.ParseDuration(saved.Dig.Rise); err != nil {
+			return err
+		}
+	}
+	*o = options
+	return nil
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(o *Options)
+		expectError bool
+	}{
+		{"default", func(o *Options) {}, false},
+		{"no lock delay", func(o *Options) { o.LockDelay = 0 }, false},
+		{"negative lock delay", func(o *Options) { o.LockDelay = -time.Second }, true},
+		{"no gravity", func(o *Options) { o.Gravity = nil }, true},
+		{"unknown scoring", func(o *Options) { o.Scoring = ScoringType(99) }, true},
+		{"unknown lock type", func(o *Options) { o.LockType = LockType(99) }, true},
+		{"wide board", func(o *Options) { o.BoardHeight, o.BoardWidth = 30, 20 }, false},
+		{"smallest board", func(o *Options) { o.BoardHeight, o.BoardWidth = 4, 4 }, false},
+		{"board too short", func(o *Options) { o.BoardHeight = 3 }, true},
+		{"board too wide", func(o *Options) { o.BoardWidth = 51 }, true},
+		{"spawn at the left wall", func(o *Options) { o.SpawnX = 1 }, false},
+		{"spawn through the left wall", func(o *Options) { o.SpawnX = 0 }, true},
+		{"spawn through the right wall", func(o *Options) { o.SpawnX = 8 }, true},
+		{"spawn above the board", func(o *Options) { o.SpawnY = -1 }, false},
+		{"spawn below the floor", func(o *Options) { o.SpawnY = 20 }, true},
+		{"spawn at the top of the buffer", func(o *Options) { o.SpawnY = -bufferRows + 1 }, false},
+		{"spawn above the buffer", func(o *Options) { o.SpawnY = -bufferRows }, true},
+		{"no queue", func(o *Options) { o.QueueSize = 0 }, true},
+		{"queue too long", func(o *Options) { o.QueueSize = 8 }, true},
+		{"sprint", func(o *Options) { o.Mode = SprintMode }, false},
+		{"sprint without lines", func(o *Options) { o.Mode, o.SprintLines = SprintMode, 0 }, true},
+		{"unknown mode", func(o *Options) { o.Mode = ModeType(99) }, true},
+		{"ultra", func(o *Options) { o.Mode = UltraMode }, false},
+		{"ultra without a time limit", func(o *Options) { o.Mode, o.UltraTime = UltraMode, 0 }, true},
+		{"dig", func(o *Options) { o.Mode, o.DigRise = DigMode, 10*time.Second }, false},
+		{"dig without garbage", func(o *Options) { o.Mode, o.DigRows = DigMode, 0 }, true},
+		{"dig with too much garbage", func(o *Options) { o.Mode, o.DigRows = DigMode, o.BoardHeight }, true},
+		{"dig too messy", func(o *Options) { o.Mode, o.DigMessiness = DigMode, 1.5 }, true},
+		{"dig garbage sinking", func(o *Options) { o.Mode, o.DigRise = DigMode, -time.Second }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			tt.modify(&options)
+
+			err := options.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Validate() expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOptions_StandardRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(o *Options)
+		standard bool
+	}{
+		{"default", func(o *Options) {}, true},
+		{"another mode", func(o *Options) { o.Mode = SprintMode }, true},
+		{"centre spawn given as a column", func(o *Options) { o.SpawnX = 5 }, true},
+		{"nes scoring", func(o *Options) { o.Scoring = NESScoring }, false},
+		{"slow gravity", func(o *Options) { o.Gravity = NewTableGravity([]float64{48}) }, false},
+		{"infinite lock", func(o *Options) { o.LockType = InfiniteLock }, false},
+		{"long lock delay", func(o *Options) { o.LockDelay = 2 * time.Second }, false},
+		{"wide board", func(o *Options) { o.BoardWidth = 12 }, false},
+		{"short queue", func(o *Options) { o.QueueSize = 1 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			tt.modify(&options)
+
+			if standard := options.standardRules(); standard != tt.standard {
+				t.Errorf("standardRules() = %v, want %v", standard, tt.standard)
+			}
+		})
+	}
+}
+
+func TestOptions_JSON(t *testing.T) {
+	options := DefaultOptions()
+	options.Scoring = NESScoring
+	options.Gravity = NewTableGravity([]float64{48, 43, 0.5})
+	options.LockType = StepResetLock
+	options.LockDelay = 250 * time.Millisecond
+	options.BoardHeight, options.BoardWidth = 24, 12
+	options.SpawnY, options.QueueSize = 1, 3
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	expected := `{"scoring":"nes","gravity":"48,43,0.5","lock":"step","lockDelay":"250ms","boardHeight":24,"boardWidth":12,"spawnX":-1,"spawnY":1,"queueSize":3,"mode":"marathon"}`
+	if string(data) != expected {
+		t.Errorf("Marshal() = %s, want %s", data, expected)
+	}
+
+	var loaded Options
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	gravity := loaded.Gravity
+	loaded.Gravity = options.Gravity // compared level by level below
+	if !reflect.DeepEqual(loaded, options) {
+		t.Errorf("Unmarshal() = %+v, want %+v", loaded, options)
+	}
+	loaded.Gravity = gravity
+	for level := 0; level < 5; level++ {
+		if loaded.Gravity.Speed(level) != options.Gravity.Speed(level) {
+			t.Errorf("Unmarshal() gravity Speed(%d) = %d, want %d", level, loaded.Gravity.Speed(level), options.Gravity.Speed(level))
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"scoring":"tgm","gravity":"nes","lock":"move","lockDelay":"1s"}`), &loaded); err == nil {
+		t.Error("Unmarshal() with unknown scoring expected error, got nil")
+	}
+}
+
+func TestOptions_JSONModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(o *Options)
+		expected string
+	}{
+		{"sprint", func(o *Options) { o.Mode, o.SprintLines = SprintMode, 20 }, `"mode":"sprint","sprintLines":20}`},
+		{"ultra", func(o *Options) { o.Mode, o.UltraTime = UltraMode, 3*time.Minute }, `"mode":"ultra","ultraTime":"3m0s"}`},
+		{"clean dig", func(o *Options) { o.Mode, o.DigRows, o.DigMessiness = DigMode, 5, 0 }, `"mode":"dig","dig":{"rows":5,"messiness":0,"rise":"0s"}}`},
+		{"rising dig", func(o *Options) { o.Mode, o.DigRise = DigMode, 10*time.Second }, `"mode":"dig","dig":{"rows":10,"messiness":1,"rise":"10s"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			tt.modify(&options)
+
+			data, err := json.Marshal(options)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(string(data), tt.expected) {
+				t.Errorf("Marshal() = %s, want it to end %s", data, tt.expected)
+			}
+
+			var loaded Options
+			if err := json.Unmarshal(data, &loaded); err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v", err)
+			}
+			loaded.Gravity = options.Gravity // gravity tables are compared by the test above
+			if !reflect.DeepEqual(loaded, options) {
+				t.Errorf("Unmarshal() = %+v, want %+v", loaded, options)
+			}
+		})
+	}
+
+	var loaded Options
+	if err := json.Unmarshal([]byte(`{"scoring":"nes","gravity":"nes","lock":"move","lockDelay":"1s"}`), &loaded); err == nil {
+		t.Error("Unmarshal() without a mode expected error, got nil")
+	}
+}
@@ -0,0 +1,234 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+const (
+	maxPlaybackSpeed = 16              // times real time
+	seekStep         = 5 * time.Second // how far the left and right keys seek
+)
+
+// Player plays a replay back through a UI. The replayed game is rebuilt from the replay's seed, generator and options,
+// and the recorded inputs are pressed on the same frames they were recorded, so the game plays out exactly as it was played.
+//
+// While playing, the pause key pauses playback, up and down speed it up and slow it down,
+// left and right seek backwards and forwards, and stop ends playback.
+type Player struct {
+	ui       ui.UI
+	clock    Clock
+	replay   *Replay
+	game     *Game
+	next     int  // index of the next input to press
+	speed    int  // frames played each tick
+	paused   bool // playback is paused, separate from the replayed game being paused
+	verified bool // the result has been checked, once the replay reached its end
+	err      error
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// NewPlayer creates a player for a replay, ready to play from the start
+func NewPlayer(ui ui.UI, clock Clock, replay *Replay) (*Player, error) {
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d, want %d", replay.Version, ReplayVersion)
+	}
+
+	p := &Player{
+		ui:     ui,
+		clock:  clock,
+		replay: replay,
+		speed:  1,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := p.restart(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// restart rebuilds the replayed game from scratch
+func (p *Player) restart() error {
+	generatorType, err := tetris.ParseGeneratorType(p.replay.Generator)
+	if err != nil {
+		return err
+	}
+	generator, err := tetris.NewGenerator(generatorType)
+	if err != nil {
+		return err
+	}
+
+	game, err := NewGame(p.ui, generator, p.replay.Seed, p.clock, p.replay.Options)
+	if err != nil {
+		return err
+	}
+	game.muted = true // the player updates the UI once it has played each tick, however many frames that was
+	p.game = &game
+	p.next = 0
+	return nil
+}
+
+// Start plays the replay in real time, a tick of the player's clock for each frame at normal speed. Like Game.Start,
+// the player is stepped before it's started, never after.
+func (p *Player) Start() (<-chan struct{}, error) {
+	if err := p.ui.Init(p.game.boardHeight, p.game.boardWidth); err != nil {
+		return nil, err
+	}
+	p.ui.Start()
+	p.game.render()
+
+	go p.run(p.clock.NewTicker(frameDuration))
+
+	return p.done, nil
+}
+
+// Stop ends playback from outside the playback loop, and waits for the loop to finish
+func (p *Player) Stop() {
+	select {
+	case p.quit <- struct{}{}:
+	case <-p.done:
+	}
+	<-p.done
+}
+
+// Err returns why playback stopped early or the replay didn't play out as recorded, nil if it matched or hasn't ended
+func (p *Player) Err() error {
+	return p.err
+}
+
+// Finished checks if every input has been pressed and the game has been played for as long as it was recorded
+func (p *Player) Finished() bool {
+	return p.next == len(p.replay.Inputs) && (p.game.frames >= p.replay.Frames || p.game.over)
+}
+
+// Verify checks the replayed game ended with the recorded result
+func (p *Player) Verify() error {
+	if !p.Finished() {
+		return fmt.Errorf("replay hasn't finished, frame %d of %d", p.game.frames, p.replay.Frames)
+	}
+	if p.game.frames != p.replay.Frames || p.game.score != p.replay.Score || p.game.cleared != p.replay.Cleared {
+		return fmt.Errorf("replay doesn't match the recording: frame %d, score %d, %d lines, want frame %d, score %d, %d lines",
+			p.game.frames, p.game.score, p.game.cleared, p.replay.Frames, p.replay.Score, p.replay.Cleared)
+	}
+	return nil
+}
+
+// Step plays the replay forward a number of frames straight away, without waiting on the clock
+func (p *Player) Step(frames int) {
+	p.playTo(p.game.frames + frames)
+}
+
+// Seek plays the replay to the given frame. Seeking backwards plays the replay again from the start.
+func (p *Player) Seek(frame int) error {
+	if frame < p.game.frames {
+		if err := p.restart(); err != nil {
+			return err
+		}
+		p.verified = false
+		p.err = nil
+	}
+	p.playTo(frame)
+	return nil
+}
+
+// Game returns the replayed game, e.g. to check its score
+func (p *Player) Game() *Game {
+	return p.game
+}
+
+// playTo plays frames until the given frame, pressing each input on the frame it was recorded.
+// Playback stops early at the end of the replay, or if the game can't move on, i.e. it's over or was left paused.
+func (p *Player) playTo(frame int) {
+	if frame > p.replay.Frames {
+		frame = p.replay.Frames
+	}
+
+	for {
+		for p.next < len(p.replay.Inputs) && p.replay.Inputs[p.next].Frame <= p.game.frames {
+			key := p.replay.Inputs[p.next].Key
+			p.next++
+			if key != ui.KeyStop { // the recording ends here, the player decides when to stop
+				p.game.Press(key)
+			}
+		}
+
+		if p.game.frames >= frame || p.game.over || p.game.pause {
+			break
+		}
+		p.game.frame()
+	}
+
+	if p.Finished() && !p.verified {
+		p.verified = true
+		p.err = p.Verify()
+		p.game.action = "Replay verified"
+		if p.err != nil {
+			p.game.action = "Replay doesn't match"
+		}
+	}
+}
+
+// run is the playback loop, like the game loop it's the only goroutine that changes the replayed game once started
+func (p *Player) run(ticker Ticker) {
+	defer ticker.Stop()
+
+	for {
+		select {
+		case keypress, ok := <-p.ui.KeyPress():
+			if !ok || keypress == ui.KeyStop {
+				p.stop()
+				return
+			}
+			if err := p.control(keypress); err != nil {
+				p.err = err
+				p.stop()
+				return
+			}
+			p.game.render()
+		case <-ticker.C():
+			if !p.paused && !p.Finished() {
+				p.Step(p.speed)
+				p.game.render()
+			}
+		case <-p.quit:
+			p.stop()
+			return
+		}
+	}
+}
+
+// control handles the player's keys for pausing, speed and seeking, returning an error if seeking fails
+func (p *Player) control(keypress ui.KeyPress) error {
+	switch keypress {
+	case ui.KeyPause:
+		p.paused = !p.paused
+	case ui.KeyUp:
+		if p.speed < maxPlaybackSpeed {
+			p.speed *= 2
+		}
+	case ui.KeyDown:
+		if p.speed > 1 {
+			p.speed /= 2
+		}
+	case ui.KeyLeft:
+		frame := p.game.frames - framesIn(seekStep)
+		if frame < 0 {
+			frame = 0
+		}
+		return p.Seek(frame)
+	case ui.KeyRight:
+		return p.Seek(p.game.frames + framesIn(seekStep))
+	}
+	return nil
+}
+
+// stop stops the UI and signals playback is done
+func (p *Player) stop() {
+	p.ui.Stop()
+	close(p.done)
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// ReplayVersion is the version of the replay format written by this version of the game.
+// Any change to the rules can play a game out differently, so replays are only played back by the version that wrote them.
+const ReplayVersion = 2
+
+// Replay is a recording of a game. Games play out the same every time from the same seed, generator, options and inputs,
+// so that is all a replay needs to play the game again. The result is kept too, to check the replay played out the same.
+type Replay struct {
+	Version   int     `json:"version"`
+	Generator string  `json:"generator"`
+	Seed      int64   `json:"seed"`
+	Options   Options `json:"options"`
+	Inputs    []Input `json:"inputs"`
+	Frames    int     `json:"frames"`
+	Score     int     `json:"score"`
+	Cleared   int     `json:"cleared"`
+}
+
+// Input is a key pressed by the player, after the given number of frames had been played
+type Input struct {
+	Frame int         `json:"frame"`
+	Key   ui.KeyPress `json:"key"`
+}
+
+// Record starts recording the game, adding every input from now on and the result once it's over or stopped
+func (g *Game) Record() *Replay {
+	g.replay = &Replay{
+		Version:   ReplayVersion,
+		Generator: g.generator.Type().String(),
+		Seed:      g.seed,
+		Options:   g.options,
+	}
+	return g.replay
+}
+
+// recordInput adds an input to the replay, if the game is being recorded
+func (g *Game) recordInput(keypress ui.KeyPress) {
+	if g.replay != nil {
+		g.replay.Inputs = append(g.replay.Inputs, Input{Frame: g.frames, Key: keypress})
+	}
+}
+
+// recordResult saves the result to the replay, if the game is being recorded
+func (g *Game) recordResult() {
+	if g.replay != nil {
+		g.replay.Frames = g.frames
+		g.replay.Score = g.score
+		g.replay.Cleared = g.cleared
+	}
+}
+
+// WriteReplay saves a replay as JSON
+func WriteReplay(w io.Writer, replay *Replay) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(replay)
+}
+
+// ReadReplay loads a replay saved by WriteReplay, checking it was written by this version of the game
+func ReadReplay(r io.Reader) (*Replay, error) {
+	var replay Replay
+	if err := json.NewDecoder(r).Decode(&replay); err != nil {
+		return nil, fmt.Errorf("reading replay: %w", err)
+	}
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version %d, want %d", replay.Version, ReplayVersion)
+	}
+	return &replay, nil
+}
@@ -0,0 +1,247 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// recordTestGame plays a game with a mix of every input, including pausing, and returns its replay
+func recordTestGame(t *testing.T) (*Game, *Replay) {
+	game := newTestGame(t)
+	replay := game.Record()
+
+	playTestGame(game, 10)
+	game.Press(ui.KeyPause)
+	game.Step(100) // nothing happens while paused
+	game.Press(ui.KeyGhost)
+	game.Press(ui.KeyPause)
+	playTestGame(game, 30)
+	game.Press(ui.KeyStop)
+
+	return game, replay
+}
+
+func newTestPlayer(t *testing.T, replay *Replay) *Player {
+	mockUI, cleanup, err := ui.NewUI(ui.Mock)
+	if err != nil {
+		t.Fatalf("failed to create mock UI: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	player, err := NewPlayer(mockUI, NewManualClock(), replay)
+	if err != nil {
+		t.Fatalf("NewPlayer() unexpected error: %v", err)
+	}
+	return player
+}
+
+func TestGame_Record(t *testing.T) {
+	game, replay := recordTestGame(t)
+
+	if replay.Version != ReplayVersion || replay.Generator != "7bag" || replay.Seed != 42 {
+		t.Errorf("replay header = version %d, generator %q, seed %d, want %d, %q, %d",
+			replay.Version, replay.Generator, replay.Seed, ReplayVersion, "7bag", 42)
+	}
+	if replay.Frames != game.Frames() || replay.Score != game.Score() || replay.Cleared != game.Cleared() {
+		t.Errorf("replay result = frame %d, score %d, %d lines, want %d, %d, %d",
+			replay.Frames, replay.Score, replay.Cleared, game.Frames(), game.Score(), game.Cleared())
+	}
+	if last := replay.Inputs[len(replay.Inputs)-1]; last.Key != ui.KeyStop || last.Frame != game.Frames() {
+		t.Errorf("last input = %+v, want stop on frame %d", last, game.Frames())
+	}
+}
+
+func TestReplay_ReadWrite(t *testing.T) {
+	_, replay := recordTestGame(t)
+
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, replay); err != nil {
+		t.Fatalf("WriteReplay() unexpected error: %v", err)
+	}
+	loaded, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("ReadReplay() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Inputs, replay.Inputs) {
+		t.Error("ReadReplay() inputs differ from those written")
+	}
+	if loaded.Seed != replay.Seed || loaded.Generator != replay.Generator || loaded.Score != replay.Score {
+		t.Errorf("ReadReplay() = %+v, want %+v", loaded, replay)
+	}
+
+	if _, err := ReadReplay(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("ReadReplay() of an unknown version expected error, got nil")
+	}
+	if _, err := ReadReplay(strings.NewReader(`not a replay`)); err == nil {
+		t.Error("ReadReplay() of garbage expected error, got nil")
+	}
+}
+
+func TestPlayer_PlaysOutTheSame(t *testing.T) {
+	game, replay := recordTestGame(t)
+
+	var buf bytes.Buffer
+	if err := WriteReplay(&buf, replay); err != nil {
+		t.Fatalf("WriteReplay() unexpected error: %v", err)
+	}
+	loaded, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatalf("ReadReplay() unexpected error: %v", err)
+	}
+
+	player := newTestPlayer(t, loaded)
+	player.Step(replay.Frames / 2)
+	if player.Finished() {
+		t.Fatal("player finished half way through")
+	}
+	if err := player.Verify(); err == nil {
+		t.Error("Verify() half way through expected error, got nil")
+	}
+
+	player.Step(replay.Frames)
+	if !player.Finished() {
+		t.Fatalf("player not finished at frame %d of %d", player.Game().Frames(), replay.Frames)
+	}
+	if err := player.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	if !reflect.DeepEqual(player.Game().board.Blocks(), game.board.Blocks()) {
+		t.Error("replayed board differs from the recorded game")
+	}
+}
+
+func TestPlayer_Seek(t *testing.T) {
+	_, replay := recordTestGame(t)
+	player := newTestPlayer(t, replay)
+
+	player.Step(replay.Frames / 2)
+	halfWay := player.Game().board.Blocks()
+	score := player.Game().Score()
+
+	if err := player.Seek(replay.Frames); err != nil {
+		t.Fatalf("Seek() unexpected error: %v", err)
+	}
+	if !player.Finished() || player.Err() != nil {
+		t.Fatalf("Seek() to the end: finished %v, err %v", player.Finished(), player.Err())
+	}
+
+	// seeking back plays the replay again from the start, to the same state
+	if err := player.Seek(replay.Frames / 2); err != nil {
+		t.Fatalf("Seek() unexpected error: %v", err)
+	}
+	if player.Game().Frames() != replay.Frames/2 {
+		t.Errorf("Seek() back to frame %d, at frame %d", replay.Frames/2, player.Game().Frames())
+	}
+	if player.Game().Score() != score || !reflect.DeepEqual(player.Game().board.Blocks(), halfWay) {
+		t.Error("Seek() back played out differently")
+	}
+}
+
+func TestPlayer_DetectsMismatch(t *testing.T) {
+	_, replay := recordTestGame(t)
+	replay.Score++
+
+	player := newTestPlayer(t, replay)
+	player.Step(replay.Frames)
+
+	if player.Err() == nil {
+		t.Error("Err() = nil for a replay with the wrong score")
+	}
+	if player.Game().action != "Replay doesn't match" {
+		t.Errorf("action = %q, want the mismatch shown", player.Game().action)
+	}
+}
+
+func TestPlayer_Start(t *testing.T) {
+	_, replay := recordTestGame(t)
+
+	mockUI, cleanup, err := ui.NewUI(ui.Mock)
+	if err != nil {
+		t.Fatalf("failed to create mock UI: %v", err)
+	}
+	defer cleanup()
+
+	clock := NewManualClock()
+	player, err := NewPlayer(mockUI, clock, replay)
+	if err != nil {
+		t.Fatalf("NewPlayer() unexpected error: %v", err)
+	}
+	if _, err := player.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	if !mockUI.(*ui.MockUI).Started {
+		t.Error("Start() didn't start the UI")
+	}
+
+	clock.Advance(frameDuration * 10)
+	player.Stop()
+
+	if frames := player.Game().Frames(); frames != 10 {
+		t.Errorf("played %d frames in 10 ticks, want 10", frames)
+	}
+}
+
+func TestPlayer_Control(t *testing.T) {
+	_, replay := recordTestGame(t)
+	player := newTestPlayer(t, replay)
+
+	player.control(ui.KeyUp)
+	player.control(ui.KeyUp)
+	if player.speed != 4 {
+		t.Errorf("speed = %d after speeding up twice, want 4", player.speed)
+	}
+	for i := 0; i < 10; i++ {
+		player.control(ui.KeyUp)
+	}
+	if player.speed != maxPlaybackSpeed {
+		t.Errorf("speed = %d, want it capped at %d", player.speed, maxPlaybackSpeed)
+	}
+	player.control(ui.KeyDown)
+	if player.speed != maxPlaybackSpeed/2 {
+		t.Errorf("speed = %d after slowing down, want %d", player.speed, maxPlaybackSpeed/2)
+	}
+
+	player.control(ui.KeyRight)
+	if frames := player.Game().Frames(); frames != framesIn(seekStep) {
+		t.Errorf("seeking forward reached frame %d, want %d", frames, framesIn(seekStep))
+	}
+	player.control(ui.KeyLeft)
+	player.control(ui.KeyLeft)
+	if frames := player.Game().Frames(); frames != 0 {
+		t.Errorf("seeking back past the start reached frame %d, want 0", frames)
+	}
+
+	player.control(ui.KeyPause)
+	if !player.paused {
+		t.Error("pause key didn't pause playback")
+	}
+}
+
+func TestPlayer_SeekError(t *testing.T) {
+	_, replay := recordTestGame(t)
+	player := newTestPlayer(t, replay)
+	clock := player.clock.(*ManualClock)
+	if _, err := player.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	clock.Advance(frameDuration * 10)
+
+	// seeking back rebuilds the game, which fails once the replay is broken
+	replay.Generator = "tgm"
+	player.ui.(*ui.MockUI).SendKeyPress(ui.KeyLeft)
+
+	select {
+	case <-player.done:
+	case <-time.After(time.Second):
+		t.Fatal("playback didn't stop when seeking failed")
+	}
+	if player.Err() == nil {
+		t.Error("Err() = nil after seeking failed, want the error")
+	}
+}
@@ -0,0 +1,288 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// SaveVersion is the version of the saved game format written by this version of the game, Resume rejects any other
+const SaveVersion = 2
+
+// emptyCell marks an empty cell of the board in a saved game
+const emptyCell = ".."
+
+// Save is the full state of a game in progress, so it can be resumed later exactly where it was left.
+//
+// The random numbers are only used to deal tetros, so rather than saving the state of the random source and generator,
+// the save counts the tetros dealt. Resuming deals the same number again from the same seed, which leaves the source
+// and the generator just as they were.
+type Save struct {
+	Version   int                `json:"version"`
+	Generator string             `json:"generator"`
+	Seed      int64              `json:"seed"`
+	Options   Options            `json:"options"`
+	Dealt     int                `json:"dealt"`
+	Board     []string           `json:"board"`            // a row of block styles named by Factory.StyleName, top row first
+	Buffer    []string           `json:"buffer,omitempty"` // rows of the hidden buffer above the board, from the highest with blocks in it down
+	Active    tetris.TetroState  `json:"active"`
+	Held      *tetris.TetroState `json:"held,omitempty"`
+	HoldUsed  bool               `json:"holdUsed"`
+	Queue     []tetris.Shape     `json:"queue"`
+	Score     int                `json:"score"`
+	Level     int                `json:"level"`
+	Cleared   int                `json:"cleared"`
+	Pieces    int                `json:"pieces"`
+	Keys      int                `json:"keys"`
+	Paused    bool               `json:"paused"`
+	ShowGhost bool               `json:"showGhost"`
+	Timers    Timers             `json:"timers"`
+	Scoring   *ScoringState      `json:"scoring,omitempty"`
+	Garbage   *GarbageState      `json:"garbage,omitempty"`
+}
+
+// Timers are the frame counts in progress when a game was saved
+type Timers struct {
+	Frames      int    `json:"frames"`
+	Fallen      int    `json:"fallen"`
+	LockTimer   int    `json:"lockTimer"`
+	LockResets  int    `json:"lockResets"`
+	LowestRow   int    `json:"lowestRow"`
+	ClearTime   int    `json:"clearTime"`
+	ClearedRows []int  `json:"clearedRows,omitempty"`
+	Rotated     bool   `json:"rotated"`
+	Action      string `json:"action,omitempty"`
+	ActionTime  int    `json:"actionTime"`
+}
+
+// ScoringState is what guideline scoring carries from one tetro to the next
+type ScoringState struct {
+	Combo      int  `json:"combo"`
+	BackToBack bool `json:"backToBack"`
+}
+
+// GarbageState is how far a dig race has got through its garbage. Like the tetros dealt, the rows of garbage made
+// are counted rather than saving the state of the random numbers that chose their holes.
+type GarbageState struct {
+	Left      int `json:"left"`
+	Made      int `json:"made"`
+	RiseTimer int `json:"riseTimer"`
+}
+
+// Save returns the full state of the game
+func (g *Game) Save() (*Save, error) {
+	board, buffer, err := g.saveBoard()
+	if err != nil {
+		return nil, err
+	}
+
+	save := &Save{
+		Version:   SaveVersion,
+		Generator: g.generator.Type().String(),
+		Seed:      g.seed,
+		Options:   g.options,
+		Dealt:     g.dealt,
+		Board:     board,
+		Buffer:    buffer,
+		Active:    g.activeTetro.State(),
+		HoldUsed:  g.holdUsed,
+		Score:     g.score,
+		Level:     g.level,
+		Cleared:   g.cleared,
+		Pieces:    g.pieces,
+		Keys:      g.keys,
+		Paused:    g.pause,
+		ShowGhost: g.showGhost,
+		Timers: Timers{
+			Frames:      g.frames,
+			Fallen:      g.fallen,
+			LockTimer:   g.lockTimer,
+			LockResets:  g.lockResets,
+			LowestRow:   g.lowestRow,
+			ClearTime:   g.clearTime,
+			ClearedRows: append([]int(nil), g.clearedRows...),
+			Rotated:     g.rotated,
+			Action:      g.action,
+			ActionTime:  g.actionTime,
+		},
+	}
+	if g.heldTetro != nil {
+		held := g.heldTetro.State()
+		save.Held = &held
+	}
+	for _, tetro := range g.tetroQueue {
+		save.Queue = append(save.Queue, tetro.Shape())
+	}
+	if scoring, ok := g.scoring.(*guidelineScoring); ok {
+		save.Scoring = &ScoringState{Combo: scoring.combo, BackToBack: scoring.backToBack}
+	}
+	if dig, ok := g.mode.(*dig); ok {
+		save.Garbage = &GarbageState{Left: g.garbage, Made: dig.made, RiseTimer: dig.riseTimer}
+	}
+	return save, nil
+}
+
+// saveBoard names the style of every block on the board, row by row. Rows of the buffer above the board are only
+// saved from the highest with blocks in it down, so nothing is saved for the buffer while it's empty.
+func (g *Game) saveBoard() (board, buffer []string, err error) {
+	top := 0
+	for y := -bufferRows; y < 0; y++ {
+		if !g.board.RowEmpty(y) {
+			top = y
+			break
+		}
+	}
+
+	rows := make([][]string, g.boardHeight-top)
+	for y := range rows {
+		rows[y] = make([]string, g.boardWidth)
+		for x := range rows[y] {
+			rows[y][x] = emptyCell
+		}
+	}
+	for _, block := range g.board.Blocks() {
+		name, ok := g.factory.StyleName(block.Style())
+		if !ok {
+			return nil, nil, fmt.Errorf("can't save block style %v", block.Style())
+		}
+		x, y := block.Coordinates()
+		rows[y-top][x] = name
+	}
+
+	saved := make([]string, len(rows))
+	for y, row := range rows {
+		saved[y] = strings.Join(row, "")
+	}
+	return saved[-top:], saved[:-top], nil
+}
+
+// Resume creates a game from a save, exactly as it was when saved
+func Resume(ui ui.UI, clock Clock, save *Save) (Game, error) {
+	if save.Version != SaveVersion {
+		return Game{}, fmt.Errorf("unsupported save version %d, want %d", save.Version, SaveVersion)
+	}
+
+	generatorType, err := tetris.ParseGeneratorType(save.Generator)
+	if err != nil {
+		return Game{}, err
+	}
+	generator, err := tetris.NewGenerator(generatorType)
+	if err != nil {
+		return Game{}, err
+	}
+
+	g, err := newGame(ui, generator, save.Seed, clock, save.Options)
+	if err != nil {
+		return Game{}, err
+	}
+
+	// deal the same tetros again, to bring the generator and random numbers back to where they were
+	for i := 0; i < save.Dealt; i++ {
+		g.deal()
+	}
+
+	if err := g.resumeBoard(save.Board, save.Buffer); err != nil {
+		return Game{}, err
+	}
+	if g.activeTetro, err = g.factory.RestoreTetro(save.Active); err != nil {
+		return Game{}, err
+	}
+	if save.Held != nil {
+		if g.heldTetro, err = g.factory.RestoreTetro(*save.Held); err != nil {
+			return Game{}, err
+		}
+	}
+	if len(save.Queue) != len(g.tetroQueue) {
+		return Game{}, fmt.Errorf("saved queue has %d tetros, want %d", len(save.Queue), len(g.tetroQueue))
+	}
+	for i, shape := range save.Queue {
+		if g.tetroQueue[i], err = g.factory.NewTetro(shape); err != nil {
+			return Game{}, err
+		}
+	}
+
+	g.holdUsed = save.HoldUsed
+	g.score = save.Score
+	g.level = save.Level
+	g.cleared = save.Cleared
+	g.pieces = save.Pieces
+	g.keys = save.Keys
+	g.pause = save.Paused
+	g.showGhost = save.ShowGhost
+	g.frames = save.Timers.Frames
+	g.fallen = save.Timers.Fallen
+	g.lockTimer = save.Timers.LockTimer
+	g.lockResets = save.Timers.LockResets
+	g.lowestRow = save.Timers.LowestRow
+	g.clearTime = save.Timers.ClearTime
+	g.clearedRows = append(g.clearedRows[:0], save.Timers.ClearedRows...)
+	g.rotated = save.Timers.Rotated
+	g.action = save.Timers.Action
+	g.actionTime = save.Timers.ActionTime
+	if scoring, ok := g.scoring.(*guidelineScoring); ok && save.Scoring != nil {
+		scoring.combo = save.Scoring.Combo
+		scoring.backToBack = save.Scoring.BackToBack
+	}
+	if dig, ok := g.mode.(*dig); ok && save.Garbage != nil {
+		dig.remake(save.Garbage.Made, g.boardWidth)
+		dig.riseTimer = save.Garbage.RiseTimer
+		g.garbage = save.Garbage.Left
+	}
+
+	return g, nil
+}
+
+// resumeBoard places the saved blocks back on the board and in the buffer above it
+func (g *Game) resumeBoard(board, buffer []string) error {
+	if len(board) != g.boardHeight {
+		return fmt.Errorf("saved board has %d rows, want %d", len(board), g.boardHeight)
+	}
+	if len(buffer) > bufferRows {
+		return fmt.Errorf("saved buffer has %d rows, want at most %d", len(buffer), bufferRows)
+	}
+
+	var blocks []tetris.Block
+	rows := append(append([]string(nil), buffer...), board...)
+	for i, row := range rows {
+		y := i - len(buffer)
+		if len(row) != g.boardWidth*len(emptyCell) {
+			return fmt.Errorf("saved board row %d is %q, want %d cells", y, row, g.boardWidth)
+		}
+		for x := 0; x < g.boardWidth; x++ {
+			name := row[x*len(emptyCell) : (x+1)*len(emptyCell)]
+			if name == emptyCell {
+				continue
+			}
+			style, err := g.factory.NamedStyle(name)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, tetris.NewBlock(x, y, style))
+		}
+	}
+	g.board.Place(blocks)
+	return nil
+}
+
+// WriteSave saves a game as JSON
+func WriteSave(w io.Writer, save *Save) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(save)
+}
+
+// ReadSave loads a game saved by WriteSave
+func ReadSave(r io.Reader) (*Save, error) {
+	var save Save
+	if err := json.NewDecoder(r).Decode(&save); err != nil {
+		return nil, fmt.Errorf("reading saved game: %w", err)
+	}
+	if save.Version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d, want %d", save.Version, SaveVersion)
+	}
+	return &save, nil
+}
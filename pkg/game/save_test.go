@@ -0,0 +1,182 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+	"github.com/garyloug/tetris/pkg/ui"
+)
+
+// saveAndResume saves the game to JSON and resumes it from there
+func saveAndResume(t *testing.T, game *Game) *Game {
+	save, err := game.Save()
+	if err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSave(&buf, save); err != nil {
+		t.Fatalf("WriteSave() unexpected error: %v", err)
+	}
+	loaded, err := ReadSave(&buf)
+	if err != nil {
+		t.Fatalf("ReadSave() unexpected error: %v", err)
+	}
+
+	resumed, err := Resume(game.ui, NewManualClock(), loaded)
+	if err != nil {
+		t.Fatalf("Resume() unexpected error: %v", err)
+	}
+	return &resumed
+}
+
+func TestGame_SaveAndResume(t *testing.T) {
+	tests := []struct {
+		name   string
+		before func(game *Game)
+	}{
+		{"new game", func(game *Game) {}},
+		{"part way through", func(game *Game) { playTestGame(game, 12) }},
+		{"while the lock delay runs", func(game *Game) {
+			playTestGame(game, 5)
+			for game.activeTetro.CanMoveDown(game.board) {
+				game.Step(1)
+			}
+			game.Step(10)
+		}},
+		{"while paused", func(game *Game) {
+			playTestGame(game, 3)
+			game.Press(ui.KeyPause)
+		}},
+		{"after a rotation", func(game *Game) {
+			playTestGame(game, 4)
+			game.Press(ui.KeyUp)
+		}},
+		{"with blocks in the buffer", func(game *Game) {
+			playTestGame(game, 3)
+			game.board.Place([]tetris.Block{tetris.NewBlock(0, -3, game.factory.GarbageStyle()), tetris.NewBlock(9, -1, game.factory.GarbageStyle())})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			tt.before(game)
+
+			resumed := saveAndResume(t, game)
+
+			original, _ := game.Save()
+			again, _ := resumed.Save()
+			if !reflect.DeepEqual(again, original) {
+				t.Errorf("resumed game saves as %+v, want %+v", again, original)
+			}
+
+			// both games play on the same from here
+			if game.pause {
+				game.Press(ui.KeyPause)
+				resumed.Press(ui.KeyPause)
+			}
+			playTestGame(game, 20)
+			playTestGame(resumed, 20)
+			if resumed.Frames() != game.Frames() || resumed.Score() != game.Score() || resumed.Cleared() != game.Cleared() {
+				t.Errorf("resumed game reached frame %d, score %d, %d lines, want %d, %d, %d",
+					resumed.Frames(), resumed.Score(), resumed.Cleared(), game.Frames(), game.Score(), game.Cleared())
+			}
+			if !reflect.DeepEqual(resumed.board.Blocks(), game.board.Blocks()) {
+				t.Error("resumed game's board differs from the original")
+			}
+		})
+	}
+}
+
+func TestGame_ResumeWithMenus(t *testing.T) {
+	tests := []struct {
+		name     string
+		pause    bool
+		expected *menu
+	}{
+		{"playing", false, nil},
+		{"paused", true, &menu{screen: pauseMenu}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			playTestGame(game, 3)
+			if tt.pause {
+				game.Press(ui.KeyPause)
+			}
+
+			resumed := saveAndResume(t, game)
+			resumed.UseMenus()
+			if !reflect.DeepEqual(resumed.menu, tt.expected) {
+				t.Fatalf("resumed game shows menu %+v, want %+v", resumed.menu, tt.expected)
+			}
+			if tt.pause {
+				press(resumed, ui.KeyEnter) // resume
+				if resumed.pause || resumed.menu != nil {
+					t.Errorf("resumed game paused %v with menu %+v after choosing resume", resumed.pause, resumed.menu)
+				}
+			}
+		})
+	}
+}
+
+func TestGame_SaveKeepsScoring(t *testing.T) {
+	game := newTestGame(t)
+	scoring := game.scoring.(*guidelineScoring)
+	scoring.combo = 3
+	scoring.backToBack = true
+
+	resumed := saveAndResume(t, game)
+
+	if resumedScoring := resumed.scoring.(*guidelineScoring); *resumedScoring != *scoring {
+		t.Errorf("resumed scoring = %+v, want %+v", *resumedScoring, *scoring)
+	}
+}
+
+func TestResume_Errors(t *testing.T) {
+	game := newTestGame(t)
+	playTestGame(game, 5)
+
+	tests := []struct {
+		name   string
+		modify func(save *Save)
+	}{
+		{"unknown version", func(save *Save) { save.Version = 99 }},
+		{"unknown generator", func(save *Save) { save.Generator = "tgm" }},
+		{"missing row", func(save *Save) { save.Board = save.Board[1:] }},
+		{"short row", func(save *Save) { save.Board[0] = "...." }},
+		{"buffer too tall", func(save *Save) { save.Buffer = make([]string, bufferRows+1) }},
+		{"unknown block", func(save *Save) { save.Board[0] = "X0" + save.Board[0][2:] }},
+		{"unknown rotation", func(save *Save) { save.Active.Rotation = 7 }},
+		{"short queue", func(save *Save) { save.Queue = save.Queue[1:] }},
+		{"invalid options", func(save *Save) { save.Options.Gravity = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			save, err := game.Save()
+			if err != nil {
+				t.Fatalf("Save() unexpected error: %v", err)
+			}
+			tt.modify(save)
+
+			if _, err := Resume(game.ui, NewManualClock(), save); err == nil {
+				t.Error("Resume() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestReadSave(t *testing.T) {
+	if _, err := ReadSave(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("ReadSave() of an unknown version expected error, got nil")
+	}
+	if _, err := ReadSave(strings.NewReader(`not a save`)); err == nil {
+		t.Error("ReadSave() of garbage expected error, got nil")
+	}
+}
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+)
+
+const (
+	GuidelineScoring ScoringType = iota
+	NESScoring
+)
+
+// ScoringType is the rule set used to score each tetro as it locks
+type ScoringType int
+
+// scoringNames maps the names used on the command line to each scoring type
+var scoringNames = map[string]ScoringType{
+	"guideline": GuidelineScoring,
+	"nes":       NESScoring,
+}
+
+// Clear describes how a tetro locked in place, for scoring
+type Clear struct {
+	Lines        int         // lines cleared
+	Spin         tetris.Spin // spin the tetro was rotated into place with
+	PerfectClear bool        // nothing is left on the board
+}
+
+// ScoreEvent is what a tetro scored when it locked, described so the UI can show it
+type ScoreEvent struct {
+	Action       string // what was scored, e.g. "Tetris" or "T-Spin Double", empty if nothing was
+	BackToBack   bool   // followed another difficult clear, i.e. a Tetris or a T-spin clearing lines
+	Combo        int    // number of clears in a row before this one
+	PerfectClear bool
+	Points       int
+}
+
+// Label describes the event, e.g. "Back-to-Back T-Spin Double, 2 Combo"
+func (e ScoreEvent) Label() string {
+	if e.Action == "" {
+		return ""
+	}
+
+	parts := []string{e.Action}
+	if e.BackToBack {
+		parts[0] = "Back-to-Back " + e.Action
+	}
+	if e.Combo > 0 {
+		parts = append(parts, fmt.Sprintf("%d Combo", e.Combo))
+	}
+	if e.PerfectClear {
+		parts = append(parts, "Perfect Clear")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Scoring scores each tetro as it locks. Scoring can depend on the tetros before, e.g. combos, so each game needs its own.
+type Scoring interface {
+	Lock(clear Clear, level int) ScoreEvent
+}
+
+func NewScoring(scoringType ScoringType) (Scoring, error) {
+	switch scoringType {
+	case GuidelineScoring:
+		return &guidelineScoring{combo: -1}, nil
+	case NESScoring:
+		return &nesScoring{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scoring type: %d", scoringType)
+	}
+}
+
+// ParseScoringType returns the scoring type for a name such as "guideline"
+func ParseScoringType(name string) (ScoringType, error) {
+	scoringType, ok := scoringNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown scoring: %q", name)
+	}
+	return scoringType, nil
+}
+
+// String returns the name of the scoring type as used on the command line, e.g. "guideline"
+func (t ScoringType) String() string {
+	for name, scoringType := range scoringNames {
+		if scoringType == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("ScoringType(%d)", int(t))
+}
+
+// lineNames are the names for clearing each number of lines at once
+var lineNames = []string{"", "Single", "Double", "Triple", "Tetris"}
+
+// nesScoring is the classic NES table, which only counts the lines cleared at once
+type nesScoring struct{}
+
+var nesPoints = []int{0, 40, 100, 300, 1200}
+
+func (s *nesScoring) Lock(clear Clear, level int) ScoreEvent {
+	return ScoreEvent{
+		Action: lineNames[clear.Lines],
+		Points: nesPoints[clear.Lines] * (level + 1),
+	}
+}
+
+// guidelineScoring follows the guideline. On top of lines cleared it scores T-spins, even those that don't clear
+// any lines, and gives bonuses for combos (clearing lines with tetro after tetro), back-to-back difficult clears
+// and perfect clears. Guideline levels start at 1, so points are multiplied by the level + 1.
+type guidelineScoring struct {
+	combo      int  // clears in a row, -1 when the last tetro didn't clear any lines
+	backToBack bool // the last clear was a difficult one
+}
+
+var (
+	// guidelinePoints for each spin, by lines cleared. A T can't clear 4 lines, and can't be a mini clearing 3.
+	guidelinePoints = map[tetris.Spin][]int{
+		tetris.NoSpin:   {0, 100, 300, 500, 800},
+		tetris.MiniSpin: {100, 200, 400},
+		tetris.FullSpin: {400, 800, 1200, 1600},
+	}
+	perfectClearPoints = []int{0, 800, 1200, 1800, 2000}
+)
+
+const (
+	comboPoints                  = 50   // per combo
+	backToBackTetrisPerfectClear = 3200 // replaces the usual perfect clear points
+)
+
+func (s *guidelineScoring) Lock(clear Clear, level int) ScoreEvent {
+	spin := clear.Spin
+	if clear.Lines >= len(guidelinePoints[spin]) {
+		spin = tetris.NoSpin // not possible with a T, score the lines alone
+	}
+
+	event := ScoreEvent{
+		Action: spinName(spin, clear.Lines),
+		Points: guidelinePoints[spin][clear.Lines],
+	}
+
+	// without lines cleared, a T-spin still scores but the combo ends and back-to-back carries on
+	if clear.Lines == 0 {
+		s.combo = -1
+		event.Points *= level + 1
+		return event
+	}
+
+	difficult := clear.Lines == 4 || spin != tetris.NoSpin
+	if difficult && s.backToBack {
+		event.BackToBack = true
+		event.Points = event.Points * 3 / 2
+	}
+	s.backToBack = difficult
+
+	s.combo++
+	if s.combo > 0 {
+		event.Combo = s.combo
+		event.Points += comboPoints * s.combo
+	}
+
+	if clear.PerfectClear {
+		event.PerfectClear = true
+		if clear.Lines == 4 && event.BackToBack {
+			event.Points += backToBackTetrisPerfectClear
+		} else {
+			event.Points += perfectClearPoints[clear.Lines]
+		}
+	}
+
+	event.Points *= level + 1
+	return event
+}
+
+// spinName names a clear, e.g. "T-Spin Mini Single" or "Triple"
+func spinName(spin tetris.Spin, lines int) string {
+	switch spin {
+	case tetris.FullSpin:
+		return strings.TrimSpace("T-Spin " + lineNames[lines])
+	case tetris.MiniSpin:
+		return strings.TrimSpace("T-Spin Mini " + lineNames[lines])
+	default:
+		return lineNames[lines]
+	}
+}
@@ -0,0 +1,250 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/garyloug/tetris/pkg/tetris"
+)
+
+func TestParseScoringType(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    ScoringType
+		expectError bool
+	}{
+		{"guideline", GuidelineScoring, false},
+		{"nes", NESScoring, false},
+		{"tgm", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoringType, err := ParseScoringType(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseScoringType(%q) expected error, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseScoringType(%q) unexpected error: %v", tt.name, err)
+			}
+			if scoringType != tt.expected {
+				t.Errorf("ParseScoringType(%q) = %v, want %v", tt.name, scoringType, tt.expected)
+			}
+			if name := scoringType.String(); name != tt.name {
+				t.Errorf("String() = %q, want %q", name, tt.name)
+			}
+		})
+	}
+}
+
+func TestNewScoring(t *testing.T) {
+	if _, err := NewScoring(ScoringType(99)); err == nil {
+		t.Error("NewScoring(99) expected error, got nil")
+	}
+}
+
+func TestNESScoring_Lock(t *testing.T) {
+	tests := []struct {
+		name     string
+		clear    Clear
+		level    int
+		expected ScoreEvent
+	}{
+		{"nothing", Clear{}, 0, ScoreEvent{}},
+		{"single", Clear{Lines: 1}, 0, ScoreEvent{Action: "Single", Points: 40}},
+		{"tetris at level 2", Clear{Lines: 4}, 2, ScoreEvent{Action: "Tetris", Points: 3600}},
+		{"spins don't count", Clear{Lines: 2, Spin: tetris.FullSpin}, 0, ScoreEvent{Action: "Double", Points: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoring, _ := NewScoring(NESScoring)
+			if event := scoring.Lock(tt.clear, tt.level); event != tt.expected {
+				t.Errorf("Lock(%+v, %d) = %+v, want %+v", tt.clear, tt.level, event, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGuidelineScoring_Lock(t *testing.T) {
+	// each test locks a sequence of tetros with one scoring, checking what the last one scored
+	tests := []struct {
+		name     string
+		clears   []Clear
+		level    int
+		expected ScoreEvent
+	}{
+		{"nothing", []Clear{{}}, 0, ScoreEvent{}},
+		{"single", []Clear{{Lines: 1}}, 0, ScoreEvent{Action: "Single", Points: 100}},
+		{"tetris at level 2", []Clear{{Lines: 4}}, 2, ScoreEvent{Action: "Tetris", Points: 2400}},
+		{"t-spin without lines", []Clear{{Spin: tetris.FullSpin}}, 0, ScoreEvent{Action: "T-Spin", Points: 400}},
+		{"t-spin mini without lines", []Clear{{Spin: tetris.MiniSpin}}, 0, ScoreEvent{Action: "T-Spin Mini", Points: 100}},
+		{"t-spin double", []Clear{{Lines: 2, Spin: tetris.FullSpin}}, 0, ScoreEvent{Action: "T-Spin Double", Points: 1200}},
+		{"t-spin mini single", []Clear{{Lines: 1, Spin: tetris.MiniSpin}}, 0, ScoreEvent{Action: "T-Spin Mini Single", Points: 200}},
+		{"mini can't clear 3 lines", []Clear{{Lines: 3, Spin: tetris.MiniSpin}}, 0, ScoreEvent{Action: "Triple", Points: 500}},
+		{
+			"back-to-back tetris",
+			[]Clear{{Lines: 4}, {}, {Lines: 4}},
+			0,
+			ScoreEvent{Action: "Tetris", BackToBack: true, Points: 1200},
+		},
+		{
+			"back-to-back t-spin after a tetris",
+			[]Clear{{Lines: 4}, {}, {Lines: 1, Spin: tetris.FullSpin}},
+			0,
+			ScoreEvent{Action: "T-Spin Single", BackToBack: true, Points: 1200},
+		},
+		{
+			"t-spin without lines keeps back-to-back going",
+			[]Clear{{Lines: 4}, {Spin: tetris.FullSpin}, {}, {Lines: 4}},
+			0,
+			ScoreEvent{Action: "Tetris", BackToBack: true, Points: 1200},
+		},
+		{
+			"easy clear ends back-to-back",
+			[]Clear{{Lines: 4}, {}, {Lines: 1}, {}, {Lines: 4}},
+			0,
+			ScoreEvent{Action: "Tetris", Points: 800},
+		},
+		{
+			"combo",
+			[]Clear{{Lines: 1}, {Lines: 1}, {Lines: 2}},
+			1,
+			ScoreEvent{Action: "Double", Combo: 2, Points: (300 + 100) * 2},
+		},
+		{
+			"tetro without lines ends the combo",
+			[]Clear{{Lines: 1}, {}, {Lines: 1}},
+			0,
+			ScoreEvent{Action: "Single", Points: 100},
+		},
+		{
+			"perfect clear single",
+			[]Clear{{Lines: 1, PerfectClear: true}},
+			0,
+			ScoreEvent{Action: "Single", PerfectClear: true, Points: 900},
+		},
+		{
+			"back-to-back tetris perfect clear",
+			[]Clear{{Lines: 4}, {}, {Lines: 4, PerfectClear: true}},
+			0,
+			ScoreEvent{Action: "Tetris", BackToBack: true, PerfectClear: true, Points: 1200 + 3200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoring, _ := NewScoring(GuidelineScoring)
+			var event ScoreEvent
+			for _, clear := range tt.clears {
+				event = scoring.Lock(clear, tt.level)
+			}
+			if event != tt.expected {
+				t.Errorf("Lock() = %+v, want %+v", event, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScoreEvent_Label(t *testing.T) {
+	tests := []struct {
+		event    ScoreEvent
+		expected string
+	}{
+		{ScoreEvent{}, ""},
+		{ScoreEvent{Action: "Single", Points: 100}, "Single"},
+		{ScoreEvent{Action: "T-Spin Double", BackToBack: true, Combo: 2}, "Back-to-Back T-Spin Double, 2 Combo"},
+		{ScoreEvent{Action: "Tetris", PerfectClear: true}, "Tetris, Perfect Clear"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if label := tt.event.Label(); label != tt.expected {
+				t.Errorf("Label() = %q, want %q", label, tt.expected)
+			}
+		})
+	}
+}
+
+// recordingScoring records what each tetro locked with
+type recordingScoring struct {
+	clears []Clear
+}
+
+func (r *recordingScoring) Lock(clear Clear, level int) ScoreEvent {
+	r.clears = append(r.clears, clear)
+	return ScoreEvent{Action: "Recorded", Points: 1}
+}
+
+func TestGame_LockScoresSpins(t *testing.T) {
+	tests := []struct {
+		name    string
+		moves   func(game *Game)
+		rotated bool
+	}{
+		{"dropped", func(game *Game) {}, false},
+		{"rotated last", func(game *Game) { game.moveTetroRight(); game.rotateTetro(game.activeTetro.Rotate) }, true},
+		{"moved after rotating", func(game *Game) { game.rotateTetro(game.activeTetro.Rotate); game.moveTetroRight() }, false},
+		{"fell after rotating", func(game *Game) { game.rotateTetro(game.activeTetro.Rotate); game.moveTetroDown() }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestGame(t)
+			scoring := &recordingScoring{}
+			game.scoring = scoring
+			for lowestRow(game.activeTetro) < game.boardHeight-6 {
+				game.activeTetro.MoveDown() // well clear of the top, so locking doesn't end the game
+			}
+
+			tt.moves(game)
+			if game.rotated != tt.rotated {
+				t.Fatalf("rotated = %v, want %v", game.rotated, tt.rotated)
+			}
+
+			// the spin is checked where the tetro locks, before it is placed on the board
+			spin := tetris.NoSpin
+			if tt.rotated {
+				spin = game.activeTetro.Spin(game.board)
+			}
+			score := game.score
+			game.lockTetro()
+
+			if len(scoring.clears) != 1 {
+				t.Fatalf("scoring locked %d tetros, want 1", len(scoring.clears))
+			}
+			if scoring.clears[0].Spin != spin {
+				t.Errorf("locked with spin %v, want %v", scoring.clears[0].Spin, spin)
+			}
+			if game.score != score+1 {
+				t.Errorf("score = %d, want %d", game.score, score+1)
+			}
+			if game.rotated {
+				t.Error("rotated should be reset for the next tetro")
+			}
+		})
+	}
+}
+
+func TestGame_ActionShownForAWhile(t *testing.T) {
+	game := newTestGame(t)
+	game.scoring = &recordingScoring{}
+	game.options.Gravity = NewTableGravity([]float64{1000}) // keep the next tetro from locking
+
+	game.hardDrop()
+	if game.action != "Recorded" {
+		t.Fatalf("action = %q, want %q", game.action, "Recorded")
+	}
+
+	game.Step(framesIn(actionDisplayTime) - 1)
+	if game.action == "" {
+		t.Error("action cleared too soon")
+	}
+	game.Step(1)
+	if game.action != "" {
+		t.Errorf("action = %q after %v, want it cleared", game.action, actionDisplayTime)
+	}
+}
@@ -5,6 +5,10 @@ type Block struct {
 	style any
 }
 
+func NewBlock(x, y int, style any) Block {
+	return Block{x: x, y: y, style: style}
+}
+
 func (b Block) Coordinates() (x, y int) {
 	return b.x, b.y
 }
@@ -25,7 +29,6 @@ func (b *Block) moveLeft() {
 	b.x--
 }
 
-// nolint:unused
 func (b *Block) moveUp() {
 	b.y--
 }
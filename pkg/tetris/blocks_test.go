@@ -4,6 +4,18 @@ import (
 	"testing"
 )
 
+func TestNewBlock(t *testing.T) {
+	block := NewBlock(3, 7, "test")
+
+	x, y := block.Coordinates()
+	if x != 3 || y != 7 {
+		t.Errorf("NewBlock() coordinates = (%d, %d), want (%d, %d)", x, y, 3, 7)
+	}
+	if block.Style() != "test" {
+		t.Errorf("NewBlock() style = %v, want %v", block.Style(), "test")
+	}
+}
+
 func TestBlock_Coordinates(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,147 @@
+package tetris
+
+// Board is the grid of stationary blocks that tetros land on.
+// Cells are stored row by row in a fixed size grid, so collision checks are a single lookup,
+// and a count of filled cells is kept for each row, so checking for a full row is also a single lookup.
+//
+// A board can have a buffer of hidden rows above the top, numbered up from -1, where the stack can build up out of
+// sight. Row 0 is always the top of the visible board.
+type Board struct {
+	height int
+	width  int
+	buffer int    // hidden rows above the top of the board
+	cells  []cell // from the top of the buffer down
+	filled []int  // number of filled cells in each row, from the top of the buffer down
+}
+
+type cell struct {
+	filled bool
+	style  any
+}
+
+// NewBoard creates an empty board with nothing hidden above it
+func NewBoard(height, width int) *Board {
+	return NewBufferedBoard(height, width, 0)
+}
+
+// NewBufferedBoard creates an empty board with a buffer of hidden rows above it
+func NewBufferedBoard(height, width, buffer int) *Board {
+	return &Board{
+		height: height,
+		width:  width,
+		buffer: buffer,
+		cells:  make([]cell, (buffer+height)*width),
+		filled: make([]int, buffer+height),
+	}
+}
+
+// Height is the number of visible rows, not counting the buffer
+func (b *Board) Height() int {
+	return b.height
+}
+
+func (b *Board) Width() int {
+	return b.width
+}
+
+// Buffer is the number of hidden rows above the top of the board
+func (b *Board) Buffer() int {
+	return b.buffer
+}
+
+// Free checks a block can be at the given position, i.e. it's inside the walls and floor of the board and the cell is empty.
+// Positions above the top of the buffer are free, as tetros spawn partially above it.
+func (b *Board) Free(x, y int) bool {
+	if x < 0 || x >= b.width || y >= b.height {
+		return false
+	}
+	if y < -b.buffer {
+		return true
+	}
+	return !b.cells[b.index(x, y)].filled
+}
+
+// index is the position of a cell in the grid
+func (b *Board) index(x, y int) int {
+	return (y+b.buffer)*b.width + x
+}
+
+// Place adds blocks to the board, e.g. when a tetro locks in place.
+// Blocks outside the board and its buffer can't be stored and are ignored.
+func (b *Board) Place(blocks []Block) {
+	for _, block := range blocks {
+		x, y := block.Coordinates()
+		if x < 0 || x >= b.width || y < -b.buffer || y >= b.height {
+			continue
+		}
+
+		c := &b.cells[b.index(x, y)]
+		if !c.filled {
+			b.filled[y+b.buffer]++
+		}
+		c.filled = true
+		c.style = block.Style()
+	}
+}
+
+// RowFull checks if every cell in the row is filled
+func (b *Board) RowFull(y int) bool {
+	return b.filled[y+b.buffer] == b.width
+}
+
+// RowEmpty checks if no cells in the row are filled
+func (b *Board) RowEmpty(y int) bool {
+	return b.filled[y+b.buffer] == 0
+}
+
+// Empty checks if no cells on the board are filled
+func (b *Board) Empty() bool {
+	for _, count := range b.filled {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ClearRow empties the row, without moving any of the rows above it
+func (b *Board) ClearRow(y int) {
+	row := b.cells[b.index(0, y):b.index(0, y+1)]
+	for x := range row {
+		row[x] = cell{}
+	}
+	b.filled[y+b.buffer] = 0
+}
+
+// RemoveRow removes the row from the board, and everything above it, buffer included, drops down a row
+func (b *Board) RemoveRow(y int) {
+	copy(b.cells[b.width:b.index(0, y+1)], b.cells[:b.index(0, y)])
+	copy(b.filled[1:y+b.buffer+1], b.filled[:y+b.buffer])
+	b.ClearRow(-b.buffer)
+}
+
+// PushUp moves every row up a row, leaving the bottom row empty, e.g. to make room for a row of garbage.
+// It returns false if the top row of the buffer had blocks in it, which are pushed off the board and lost.
+func (b *Board) PushUp() bool {
+	fits := b.filled[0] == 0
+	copy(b.cells, b.cells[b.width:])
+	copy(b.filled, b.filled[1:])
+	b.ClearRow(b.height - 1)
+	return fits
+}
+
+// Blocks returns a block for each filled cell on the board, including any in the buffer
+func (b *Board) Blocks() []Block {
+	blocks := []Block{}
+	for y := -b.buffer; y < b.height; y++ {
+		if b.RowEmpty(y) {
+			continue
+		}
+		for x := 0; x < b.width; x++ {
+			if c := b.cells[b.index(x, y)]; c.filled {
+				blocks = append(blocks, Block{x: x, y: y, style: c.style})
+			}
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,248 @@
+package tetris
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestBoard creates a board with the given stationary blocks already placed
+func newTestBoard(boardHeight, boardWidth int, stationaryBlocks []Block) *Board {
+	board := NewBoard(boardHeight, boardWidth)
+	board.Place(stationaryBlocks)
+	return board
+}
+
+func TestNewBoard(t *testing.T) {
+	board := NewBoard(20, 10)
+
+	if board.Height() != 20 {
+		t.Errorf("NewBoard() Height() = %v, want %v", board.Height(), 20)
+	}
+	if board.Width() != 10 {
+		t.Errorf("NewBoard() Width() = %v, want %v", board.Width(), 10)
+	}
+	if len(board.Blocks()) != 0 {
+		t.Errorf("NewBoard() should be empty, got %d blocks", len(board.Blocks()))
+	}
+	for y := 0; y < 20; y++ {
+		if !board.RowEmpty(y) {
+			t.Errorf("NewBoard() row %d should be empty", y)
+		}
+	}
+}
+
+func TestBoard_Free(t *testing.T) {
+	board := newTestBoard(20, 10, []Block{{x: 3, y: 5, style: "block"}})
+
+	tests := []struct {
+		name     string
+		x, y     int
+		expected bool
+	}{
+		{"empty cell", 4, 5, true},
+		{"filled cell", 3, 5, false},
+		{"left of the left wall", -1, 5, false},
+		{"right of the right wall", 10, 5, false},
+		{"below the floor", 3, 20, false},
+		{"above the top", 3, -2, true},
+		{"above the top but outside the walls", 10, -2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := board.Free(tt.x, tt.y); result != tt.expected {
+				t.Errorf("Free(%d, %d) = %v, want %v", tt.x, tt.y, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBoard_Place(t *testing.T) {
+	board := NewBoard(20, 10)
+	board.Place([]Block{
+		{x: 0, y: 19, style: "a"},
+		{x: 1, y: 19, style: "b"},
+		{x: 1, y: 19, style: "c"}, // placing on a filled cell replaces it
+		{x: 5, y: -1, style: "d"}, // above the board, ignored
+	})
+
+	blocks := board.Blocks()
+	if len(blocks) != 2 {
+		t.Fatalf("Place() stored %d blocks, want 2", len(blocks))
+	}
+
+	expected := []Block{{x: 0, y: 19, style: "a"}, {x: 1, y: 19, style: "c"}}
+	for i, block := range blocks {
+		if block != expected[i] {
+			t.Errorf("Blocks()[%d] = %+v, want %+v", i, block, expected[i])
+		}
+	}
+
+	if board.RowEmpty(19) {
+		t.Error("RowEmpty(19) = true, want false")
+	}
+	if board.RowFull(19) {
+		t.Error("RowFull(19) = true, want false")
+	}
+}
+
+func TestBoard_RowFull(t *testing.T) {
+	board := NewBoard(4, 3)
+	board.Place([]Block{{x: 0, y: 3}, {x: 1, y: 3}})
+	if board.RowFull(3) {
+		t.Error("RowFull(3) = true with a gap in the row")
+	}
+
+	board.Place([]Block{{x: 2, y: 3}})
+	if !board.RowFull(3) {
+		t.Error("RowFull(3) = false with every cell filled")
+	}
+}
+
+func TestBoard_ClearRow(t *testing.T) {
+	board := newTestBoard(4, 3, []Block{
+		{x: 0, y: 2}, {x: 1, y: 2}, {x: 2, y: 2},
+		{x: 1, y: 3},
+	})
+
+	board.ClearRow(2)
+
+	if !board.RowEmpty(2) {
+		t.Error("ClearRow(2) did not empty the row")
+	}
+	if !board.Free(0, 2) || !board.Free(1, 2) || !board.Free(2, 2) {
+		t.Error("ClearRow(2) left filled cells in the row")
+	}
+	if board.Free(1, 3) {
+		t.Error("ClearRow(2) changed the row below")
+	}
+}
+
+func TestBoard_RemoveRow(t *testing.T) {
+	board := newTestBoard(4, 3, []Block{
+		{x: 0, y: 0, style: "top"},
+		{x: 1, y: 1, style: "above"},
+		{x: 0, y: 2, style: "full"}, {x: 1, y: 2, style: "full"}, {x: 2, y: 2, style: "full"},
+		{x: 2, y: 3, style: "below"},
+	})
+
+	board.RemoveRow(2)
+
+	expected := []Block{
+		{x: 0, y: 1, style: "top"},
+		{x: 1, y: 2, style: "above"},
+		{x: 2, y: 3, style: "below"},
+	}
+	blocks := board.Blocks()
+	if len(blocks) != len(expected) {
+		t.Fatalf("RemoveRow(2) left %d blocks, want %d", len(blocks), len(expected))
+	}
+	for i, block := range blocks {
+		if block != expected[i] {
+			t.Errorf("Blocks()[%d] = %+v, want %+v", i, block, expected[i])
+		}
+	}
+
+	if !board.RowEmpty(0) {
+		t.Error("RemoveRow(2) should leave the top row empty")
+	}
+	for y, count := range []int{0, 1, 1, 1} {
+		if board.filled[y] != count {
+			t.Errorf("RemoveRow(2) row %d filled count = %d, want %d", y, board.filled[y], count)
+		}
+	}
+}
+
+func TestBoard_PushUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []Block
+		fits     bool
+		expected []Block
+	}{
+		{
+			name:     "room above",
+			blocks:   []Block{{x: 1, y: 2, style: "a"}, {x: 0, y: 3, style: "b"}, {x: 2, y: 3, style: "b"}},
+			fits:     true,
+			expected: []Block{{x: 1, y: 1, style: "a"}, {x: 0, y: 2, style: "b"}, {x: 2, y: 2, style: "b"}},
+		},
+		{
+			name:     "pushed off the top",
+			blocks:   []Block{{x: 0, y: 0, style: "top"}, {x: 2, y: 3, style: "b"}},
+			fits:     false,
+			expected: []Block{{x: 2, y: 2, style: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := newTestBoard(4, 3, tt.blocks)
+
+			if fits := board.PushUp(); fits != tt.fits {
+				t.Errorf("PushUp() = %v, want %v", fits, tt.fits)
+			}
+			if blocks := board.Blocks(); !reflect.DeepEqual(blocks, tt.expected) {
+				t.Errorf("Blocks() = %+v, want %+v", blocks, tt.expected)
+			}
+			if !board.RowEmpty(3) {
+				t.Error("PushUp() should leave the bottom row empty")
+			}
+		})
+	}
+}
+
+func TestBoard_Buffer(t *testing.T) {
+	board := NewBufferedBoard(4, 3, 2)
+	if board.Height() != 4 || board.Buffer() != 2 {
+		t.Errorf("NewBufferedBoard() Height() = %d, Buffer() = %d, want 4 and 2", board.Height(), board.Buffer())
+	}
+
+	board.Place([]Block{
+		{x: 0, y: -3, style: "above"}, // above the buffer, ignored
+		{x: 0, y: -2, style: "hidden"},
+		{x: 1, y: -1, style: "hidden"},
+		{x: 0, y: 1, style: "full"}, {x: 1, y: 1, style: "full"}, {x: 2, y: 1, style: "full"},
+	})
+
+	if !board.Free(0, -3) || board.Free(0, -2) || board.Free(1, -1) || !board.Free(2, -1) {
+		t.Error("Free() doesn't match the blocks placed in the buffer")
+	}
+
+	// the buffer drops into sight when a row below it is removed
+	board.RemoveRow(1)
+	expected := []Block{{x: 0, y: -1, style: "hidden"}, {x: 1, y: 0, style: "hidden"}}
+	if blocks := board.Blocks(); !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("RemoveRow(1) Blocks() = %+v, want %+v", blocks, expected)
+	}
+	if !board.RowEmpty(-2) {
+		t.Error("RemoveRow(1) should leave the top of the buffer empty")
+	}
+
+	// it only overflows once the top of the buffer is pushed off
+	if !board.PushUp() {
+		t.Error("PushUp() = false with the top of the buffer empty")
+	}
+	if board.PushUp() {
+		t.Error("PushUp() = true with blocks at the top of the buffer")
+	}
+	if blocks := board.Blocks(); !reflect.DeepEqual(blocks, []Block{{x: 1, y: -2, style: "hidden"}}) {
+		t.Errorf("PushUp() Blocks() = %+v, want the block pushed to the top of the buffer", blocks)
+	}
+}
+
+func TestBoard_Empty(t *testing.T) {
+	board := NewBoard(4, 3)
+	if !board.Empty() {
+		t.Error("Empty() = false for a new board")
+	}
+
+	board.Place([]Block{{x: 1, y: 0}})
+	if board.Empty() {
+		t.Error("Empty() = true with a filled cell")
+	}
+
+	board.ClearRow(0)
+	if !board.Empty() {
+		t.Error("Empty() = false after clearing the only filled row")
+	}
+}
@@ -0,0 +1,105 @@
+package tetris
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// defaultFactory is only used by the package level Init and NewRandomTetro
+var defaultFactory = NewFactory(Config{})
+
+// Factory creates tetros for a single game, at that game's spawn position and with its styles.
+// Every tetro remembers the factory that created it, so it can respawn with the same settings.
+// Each game owns its own factory, so many games can run side by side in one process.
+type Factory struct {
+	config Config
+}
+
+func NewFactory(cfg Config) *Factory {
+	return &Factory{config: cfg}
+}
+
+// NewRandomTetro creates a tetro of a random shape
+func (f *Factory) NewRandomTetro(rng *rand.Rand) Tetro {
+	return tetroFactories[rng.Intn(len(tetroFactories))](f)
+}
+
+// NewTetro creates a tetro of the given shape
+func (f *Factory) NewTetro(shape Shape) (Tetro, error) {
+	if shape < ShapeO || shape > ShapeT {
+		return nil, fmt.Errorf("unknown shape: %d", int(shape))
+	}
+	return tetroFactories[shape](f), nil
+}
+
+// RestoreTetro creates a tetro from its state, in the same position and rotation it was in
+func (f *Factory) RestoreTetro(state TetroState) (Tetro, error) {
+	if state.Rotation < 0 || state.Rotation > 3 {
+		return nil, fmt.Errorf("unknown rotation: %d", state.Rotation)
+	}
+	tetro, err := f.NewTetro(state.Shape)
+	if err != nil {
+		return nil, err
+	}
+	tetro.restore(state)
+	return tetro, nil
+}
+
+// garbageStyleName names the garbage style, which doesn't belong to any shape
+const garbageStyleName = "GB"
+
+// GarbageStyle is the style of garbage blocks
+func (f *Factory) GarbageStyle() any {
+	return f.config.StyleGarbage
+}
+
+// StyleName names a block style by the shape and block it belongs to, e.g. "T2" for block 2 of the T shape, or "GB" for garbage,
+// so blocks can be saved without knowing anything about the UI's styles. It returns false if the style isn't one of the factory's.
+func (f *Factory) StyleName(style any) (string, bool) {
+	for shape, styles := range f.shapeStyles() {
+		for i, blockStyle := range []any{styles.Block0, styles.Block1, styles.Block2, styles.Block3} {
+			if blockStyle == style {
+				return Shape(shape).String() + strconv.Itoa(i), true
+			}
+		}
+	}
+	if f.config.StyleGarbage != nil && style == f.config.StyleGarbage {
+		return garbageStyleName, true
+	}
+	return "", false
+}
+
+// NamedStyle returns the block style for a name given by StyleName
+func (f *Factory) NamedStyle(name string) (any, error) {
+	if name == garbageStyleName && f.config.StyleGarbage != nil {
+		return f.config.StyleGarbage, nil
+	}
+	if len(name) != 2 {
+		return nil, fmt.Errorf("unknown block style: %q", name)
+	}
+	shape, err := ParseShape(name[:1])
+	if err != nil {
+		return nil, fmt.Errorf("unknown block style: %q", name)
+	}
+
+	styles := f.shapeStyles()[shape]
+	switch name[1] {
+	case '0':
+		return styles.Block0, nil
+	case '1':
+		return styles.Block1, nil
+	case '2':
+		return styles.Block2, nil
+	case '3':
+		return styles.Block3, nil
+	default:
+		return nil, fmt.Errorf("unknown block style: %q", name)
+	}
+}
+
+// shapeStyles returns the styles for each shape, in shape order
+func (f *Factory) shapeStyles() []BlockStyles {
+	c := f.config
+	return []BlockStyles{c.StyleO, c.StyleI, c.StyleS, c.StyleZ, c.StyleL, c.StyleJ, c.StyleT}
+}
@@ -0,0 +1,216 @@
+package tetris
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFactory_Independent(t *testing.T) {
+	left := NewFactory(Config{
+		SpawnX: 5,
+		SpawnY: 0,
+		StyleO: BlockStyles{Block0: "left", Block1: "left", Block2: "left", Block3: "left"},
+	})
+	right := NewFactory(Config{
+		SpawnX: 15,
+		SpawnY: 2,
+		StyleO: BlockStyles{Block0: "right", Block1: "right", Block2: "right", Block3: "right"},
+	})
+
+	leftO := left.newO()
+	rightO := right.newO()
+
+	// the package level config must not affect factories
+	Init(Config{SpawnX: 50, SpawnY: 50})
+
+	tests := []struct {
+		name      string
+		tetro     Tetro
+		expectedX int
+		expectedY int
+		style     string
+	}{
+		{"left factory", leftO, 5, 0, "left"},
+		{"right factory", rightO, 15, 2, "right"},
+		{"left factory respawn", leftO.Spawn(), 5, 0, "left"},
+		{"right factory respawn", rightO.Spawn(), 15, 2, "right"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := tt.tetro.Blocks()[0].Coordinates()
+			if x != tt.expectedX || y != tt.expectedY {
+				t.Errorf("block 0 at (%d,%d), want (%d,%d)", x, y, tt.expectedX, tt.expectedY)
+			}
+			for i, block := range tt.tetro.Blocks() {
+				if block.Style() != tt.style {
+					t.Errorf("block %d style = %v, want %v", i, block.Style(), tt.style)
+				}
+			}
+		})
+	}
+}
+
+func TestFactory_NewRandomTetro(t *testing.T) {
+	f := NewFactory(Config{
+		SpawnX: 5,
+		SpawnY: 0,
+		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
+		StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
+		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
+		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
+		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
+		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
+		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
+	})
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		tetro := f.NewRandomTetro(rng)
+		if len(tetro.Blocks()) != 4 {
+			t.Fatalf("NewRandomTetro() should return tetro with 4 blocks, got %d", len(tetro.Blocks()))
+		}
+		for j, block := range tetro.Blocks() {
+			if block.Style() == nil {
+				t.Errorf("NewRandomTetro() %T block %d has nil style", tetro, j)
+			}
+		}
+	}
+}
+
+// run with -race to check factories can be used from many goroutines at once
+func TestFactory_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(spawnX int) {
+			defer wg.Done()
+			f := NewFactory(Config{SpawnX: spawnX, SpawnY: 0})
+			rng := rand.New(rand.NewSource(int64(spawnX)))
+			board := NewBoard(20, 20)
+			for i := 0; i < 100; i++ {
+				tetro := f.NewRandomTetro(rng)
+				tetro.Rotate(board)
+				spawned := tetro.Spawn()
+				x, _ := spawned.Blocks()[0].Coordinates()
+				if x < spawnX-1 || x > spawnX+1 {
+					t.Errorf("%T spawned at x = %d by factory with SpawnX %d", spawned, x, spawnX)
+					return
+				}
+			}
+		}(g + 2)
+	}
+	wg.Wait()
+}
+
+// newStyledTestFactory creates a factory with a different style for every block of every shape
+func newStyledTestFactory() *Factory {
+	styles := func(shape string) BlockStyles {
+		return BlockStyles{Block0: shape + "0", Block1: shape + "1", Block2: shape + "2", Block3: shape + "3", Ghost: shape + "ghost"}
+	}
+	return NewFactory(Config{
+		SpawnX: 5,
+		StyleO: styles("O"),
+		StyleI: styles("I"),
+		StyleS: styles("S"),
+		StyleZ: styles("Z"),
+		StyleL: styles("L"),
+		StyleJ: styles("J"),
+		StyleT: styles("T"),
+	})
+}
+
+func TestFactory_NewTetro(t *testing.T) {
+	f := newStyledTestFactory()
+
+	for shape := ShapeO; shape <= ShapeT; shape++ {
+		tetro, err := f.NewTetro(shape)
+		if err != nil {
+			t.Fatalf("NewTetro(%v) unexpected error: %v", shape, err)
+		}
+		if tetro.Shape() != shape {
+			t.Errorf("NewTetro(%v).Shape() = %v", shape, tetro.Shape())
+		}
+		if tetro.Spawn().Shape() != shape {
+			t.Errorf("NewTetro(%v).Spawn().Shape() = %v", shape, tetro.Spawn().Shape())
+		}
+		if style := tetro.Blocks()[0].Style(); style != shape.String()+"0" {
+			t.Errorf("NewTetro(%v) style = %v, want %v", shape, style, shape.String()+"0")
+		}
+	}
+
+	if _, err := f.NewTetro(Shape(7)); err == nil {
+		t.Error("NewTetro(7) expected error, got nil")
+	}
+}
+
+func TestFactory_RestoreTetro(t *testing.T) {
+	f := newStyledTestFactory()
+	board := NewBoard(20, 10)
+
+	for shape := ShapeO; shape <= ShapeT; shape++ {
+		original, _ := f.NewTetro(shape)
+		original.MoveDown()
+		original.MoveDown()
+		original.MoveLeft()
+		original.Rotate(board)
+
+		restored, err := f.RestoreTetro(original.State())
+		if err != nil {
+			t.Fatalf("RestoreTetro(%+v) unexpected error: %v", original.State(), err)
+		}
+		if restored.State() != original.State() {
+			t.Errorf("RestoreTetro() state = %+v, want %+v", restored.State(), original.State())
+		}
+		if !reflect.DeepEqual(restored.Blocks(), original.Blocks()) {
+			t.Errorf("RestoreTetro() blocks = %v, want %v", restored.Blocks(), original.Blocks())
+		}
+	}
+
+	if _, err := f.RestoreTetro(TetroState{Shape: ShapeT, Rotation: 4}); err == nil {
+		t.Error("RestoreTetro() with rotation 4 expected error, got nil")
+	}
+	if _, err := f.RestoreTetro(TetroState{Shape: Shape(-1)}); err == nil {
+		t.Error("RestoreTetro() with an unknown shape expected error, got nil")
+	}
+}
+
+func TestFactory_StyleName(t *testing.T) {
+	f := newStyledTestFactory()
+
+	for shape := ShapeO; shape <= ShapeT; shape++ {
+		tetro, _ := f.NewTetro(shape)
+		for _, block := range tetro.Blocks() {
+			name, ok := f.StyleName(block.Style())
+			if !ok || name != block.Style() {
+				t.Errorf("StyleName(%v) = %q, %v, want %q", block.Style(), name, ok, block.Style())
+			}
+			style, err := f.NamedStyle(name)
+			if err != nil || style != block.Style() {
+				t.Errorf("NamedStyle(%q) = %v, %v, want %v", name, style, err, block.Style())
+			}
+		}
+	}
+
+	if _, ok := f.StyleName("garbage"); ok {
+		t.Error("StyleName() of a style the factory doesn't have should be false")
+	}
+	if _, err := f.NamedStyle("GB"); err == nil {
+		t.Error("NamedStyle(\"GB\") of a factory without a garbage style expected error, got nil")
+	}
+
+	f.config.StyleGarbage = "garbage"
+	if name, ok := f.StyleName(f.GarbageStyle()); !ok || name != "GB" {
+		t.Errorf("StyleName(garbage) = %q, %v, want \"GB\"", name, ok)
+	}
+	if style, err := f.NamedStyle("GB"); err != nil || style != "garbage" {
+		t.Errorf("NamedStyle(\"GB\") = %v, %v, want garbage", style, err)
+	}
+	for _, name := range []string{"", "T", "T4", "X0", "T00"} {
+		if _, err := f.NamedStyle(name); err == nil {
+			t.Errorf("NamedStyle(%q) expected error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tetris
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	RandomGenerator GeneratorType = iota
+	Bag7Generator
+	Bag14Generator
+	NESGenerator
+)
+
+type GeneratorType int
+
+// generatorNames maps the names used on the command line to each generator type
+var generatorNames = map[string]GeneratorType{
+	"random": RandomGenerator,
+	"7bag":   Bag7Generator,
+	"14bag":  Bag14Generator,
+	"nes":    NESGenerator,
+}
+
+// Generator decides the order in which new tetros are dealt.
+// Generators have no random source of their own, the game passes in its own seeded source,
+// so the same seed always deals the same sequence. The game also passes in its own factory to create the tetros.
+type Generator interface {
+	Next(factory *Factory, rng *rand.Rand) Tetro
+	Reset() // forgets the tetros dealt so far, so the generator deals as if new
+	Type() GeneratorType
+}
+
+func NewGenerator(generatorType GeneratorType) (Generator, error) {
+	switch generatorType {
+	case RandomGenerator:
+		return &randomGenerator{}, nil
+	case Bag7Generator:
+		return &bagGenerator{copies: 1}, nil
+	case Bag14Generator:
+		return &bagGenerator{copies: 2}, nil
+	case NESGenerator:
+		return &nesGenerator{previous: -1}, nil
+	default:
+		return nil, fmt.Errorf("unsupported generator type: %d", generatorType)
+	}
+}
+
+// ParseGeneratorType returns the generator type for a name such as "7bag"
+func ParseGeneratorType(name string) (GeneratorType, error) {
+	generatorType, ok := generatorNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown generator: %q", name)
+	}
+	return generatorType, nil
+}
+
+// String returns the name of the generator type as used on the command line, e.g. "7bag"
+func (t GeneratorType) String() string {
+	for name, generatorType := range generatorNames {
+		if generatorType == t {
+			return name
+		}
+	}
+	return fmt.Sprintf("GeneratorType(%d)", int(t))
+}
+
+// randomGenerator picks every tetro independently, so long droughts and floods of a shape are possible
+type randomGenerator struct{}
+
+func (g *randomGenerator) Next(factory *Factory, rng *rand.Rand) Tetro {
+	return factory.NewRandomTetro(rng)
+}
+
+func (g *randomGenerator) Reset() {}
+
+func (g *randomGenerator) Type() GeneratorType {
+	return RandomGenerator
+}
+
+// bagGenerator deals a shuffled bag holding a number of copies of each shape, refilling it once empty.
+// With 1 copy (7-bag) a shape can never be more than 12 tetros apart, with 2 copies (14-bag) the order is looser.
+type bagGenerator struct {
+	copies int
+	bag    []int
+}
+
+func (g *bagGenerator) Next(factory *Factory, rng *rand.Rand) Tetro {
+	if len(g.bag) == 0 {
+		for c := 0; c < g.copies; c++ {
+			for shape := range tetroFactories {
+				g.bag = append(g.bag, shape)
+			}
+		}
+		rng.Shuffle(len(g.bag), func(i, j int) {
+			g.bag[i], g.bag[j] = g.bag[j], g.bag[i]
+		})
+	}
+
+	shape := g.bag[0]
+	g.bag = g.bag[1:]
+	return tetroFactories[shape](factory)
+}
+
+func (g *bagGenerator) Reset() {
+	g.bag = nil
+}
+
+func (g *bagGenerator) Type() GeneratorType {
+	if g.copies == 2 {
+		return Bag14Generator
+	}
+	return Bag7Generator
+}
+
+// nesGenerator follows the classic NES algorithm. It rolls an 8 sided die, where the 8th side
+// means "roll again". It also rolls again on a repeat of the previous shape, but only once,
+// so repeats are less likely but still possible.
+type nesGenerator struct {
+	previous int
+}
+
+func (g *nesGenerator) Next(factory *Factory, rng *rand.Rand) Tetro {
+	shape := rng.Intn(len(tetroFactories) + 1)
+	if shape == len(tetroFactories) || shape == g.previous {
+		shape = rng.Intn(len(tetroFactories))
+	}
+	g.previous = shape
+	return tetroFactories[shape](factory)
+}
+
+func (g *nesGenerator) Reset() {
+	g.previous = -1
+}
+
+func (g *nesGenerator) Type() GeneratorType {
+	return NESGenerator
+}
@@ -0,0 +1,181 @@
+package tetris
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// newGeneratorTestFactory creates a factory with a style for every shape
+func newGeneratorTestFactory() *Factory {
+	return NewFactory(Config{
+		SpawnX: 5,
+		SpawnY: 0,
+		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
+		StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
+		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
+		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
+		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
+		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
+		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
+	})
+}
+
+func TestNewGenerator(t *testing.T) {
+	factory := newGeneratorTestFactory()
+
+	tests := []struct {
+		name          string
+		generatorType GeneratorType
+		expectError   bool
+	}{
+		{"random", RandomGenerator, false},
+		{"7 bag", Bag7Generator, false},
+		{"14 bag", Bag14Generator, false},
+		{"NES", NESGenerator, false},
+		{"invalid", GeneratorType(999), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := NewGenerator(tt.generatorType)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("NewGenerator(%v) expected error, got nil", tt.generatorType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewGenerator(%v) unexpected error: %v", tt.generatorType, err)
+			}
+			if generatorType := generator.Type(); generatorType != tt.generatorType {
+				t.Errorf("Type() = %v, want %v", generatorType, tt.generatorType)
+			}
+
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				tetro := generator.Next(factory, rng)
+				if tetro == nil {
+					t.Fatal("Next() returned nil")
+				}
+				if len(tetro.Blocks()) != 4 {
+					t.Errorf("Next() should return tetro with 4 blocks, got %d", len(tetro.Blocks()))
+				}
+			}
+		})
+	}
+}
+
+func TestParseGeneratorType(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    GeneratorType
+		expectError bool
+	}{
+		{"random", RandomGenerator, false},
+		{"7bag", Bag7Generator, false},
+		{"14bag", Bag14Generator, false},
+		{"nes", NESGenerator, false},
+		{"tgm", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatorType, err := ParseGeneratorType(tt.name)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseGeneratorType(%q) expected error, got nil", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseGeneratorType(%q) unexpected error: %v", tt.name, err)
+			}
+			if generatorType != tt.expected {
+				t.Errorf("ParseGeneratorType(%q) = %v, want %v", tt.name, generatorType, tt.expected)
+			}
+			if name := generatorType.String(); name != tt.name {
+				t.Errorf("String() = %q, want %q", name, tt.name)
+			}
+		})
+	}
+}
+
+func TestBagGenerator_DealsEveryShapeOncePerBag(t *testing.T) {
+	factory := newGeneratorTestFactory()
+
+	rng := rand.New(rand.NewSource(1))
+	for _, copies := range []int{1, 2} {
+		generator := &bagGenerator{copies: copies}
+		bagSize := copies * len(tetroFactories)
+
+		for bag := 0; bag < 10; bag++ {
+			counts := map[string]int{}
+			for i := 0; i < bagSize; i++ {
+				counts[fmt.Sprintf("%T", generator.Next(factory, rng))]++
+			}
+
+			if len(counts) != len(tetroFactories) {
+				t.Errorf("%d copy bag %d dealt %d different shapes, want %d", copies, bag, len(counts), len(tetroFactories))
+			}
+			for shape, count := range counts {
+				if count != copies {
+					t.Errorf("%d copy bag %d dealt %s %d times, want %d", copies, bag, shape, count, copies)
+				}
+			}
+		}
+	}
+}
+
+func TestNESGenerator_RemembersPrevious(t *testing.T) {
+	factory := newGeneratorTestFactory()
+
+	rng := rand.New(rand.NewSource(1))
+	generator := &nesGenerator{previous: -1}
+	for i := 0; i < 100; i++ {
+		tetro := generator.Next(factory, rng)
+		if fmt.Sprintf("%T", tetro) != fmt.Sprintf("%T", tetroFactories[generator.previous](factory)) {
+			t.Fatalf("previous = %d does not match the dealt tetro %T", generator.previous, tetro)
+		}
+	}
+}
+
+func TestGenerator_SameSeedSameSequence(t *testing.T) {
+	factory := newGeneratorTestFactory()
+
+	for _, generatorType := range []GeneratorType{RandomGenerator, Bag7Generator, Bag14Generator, NESGenerator} {
+		generatorA, _ := NewGenerator(generatorType)
+		generatorB, _ := NewGenerator(generatorType)
+		rngA := rand.New(rand.NewSource(42))
+		rngB := rand.New(rand.NewSource(42))
+
+		for i := 0; i < 100; i++ {
+			a, b := generatorA.Next(factory, rngA), generatorB.Next(factory, rngB)
+			if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+				t.Fatalf("generator %d tetro %d: got %T and %T from the same seed", generatorType, i, a, b)
+			}
+		}
+	}
+}
+
+func TestGenerator_Reset(t *testing.T) {
+	factory := newGeneratorTestFactory()
+
+	for _, generatorType := range []GeneratorType{RandomGenerator, Bag7Generator, Bag14Generator, NESGenerator} {
+		used, _ := NewGenerator(generatorType)
+		for i := 0; i < 10; i++ {
+			used.Next(factory, rand.New(rand.NewSource(1)))
+		}
+		used.Reset()
+
+		fresh, _ := NewGenerator(generatorType)
+		rngA := rand.New(rand.NewSource(42))
+		rngB := rand.New(rand.NewSource(42))
+		for i := 0; i < 100; i++ {
+			a, b := used.Next(factory, rngA), fresh.Next(factory, rngB)
+			if a.Shape() != b.Shape() {
+				t.Fatalf("generator %v tetro %d: reset generator dealt %v, new generator dealt %v", generatorType, i, a.Shape(), b.Shape())
+			}
+		}
+	}
+}
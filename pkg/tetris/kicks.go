@@ -0,0 +1,70 @@
+package tetris
+
+// offset is a relative (x, y) position.
+// Note that y increases down the board, so the y values are inverted compared to the published SRS tables.
+type offset struct{ x, y int }
+
+// rotationStates holds the offsets of block0 to block3 from the tetro position for each of the 4 rotation states.
+// State 0 is the spawn state, each following state is a clockwise rotation of the previous one.
+type rotationStates [4][4]offset
+
+// kickTable holds the wall kick offsets for rotating out of each rotation state, for each direction of rotation.
+// The offsets are tried in order and the first one where the rotated tetro fits is used.
+type kickTable struct {
+	right [4][]offset // clockwise
+	left  [4][]offset // counter-clockwise
+	flip  [4][]offset // 180 degrees
+}
+
+var (
+	// jlstzKicks is shared by the J, L, S, T and Z shapes
+	jlstzKicks = newKickTable([4][]offset{
+		{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},  // 0 -> R
+		{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},    // R -> 2
+		{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},     // 2 -> L
+		{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, // L -> 0
+	}, flipKicks)
+
+	iKicks = newKickTable([4][]offset{
+		{{0, 0}, {-2, 0}, {1, 0}, {-2, 1}, {1, -2}}, // 0 -> R
+		{{0, 0}, {-1, 0}, {2, 0}, {-1, -2}, {2, 1}}, // R -> 2
+		{{0, 0}, {2, 0}, {-1, 0}, {2, -1}, {-1, 2}}, // 2 -> L
+		{{0, 0}, {1, 0}, {-2, 0}, {1, 2}, {-2, -1}}, // L -> 0
+	}, flipKicks)
+
+	// the O shape never needs to kick, it rotates in place
+	oKicks = newKickTable([4][]offset{
+		{{0, 0}},
+		{{0, 0}},
+		{{0, 0}},
+		{{0, 0}},
+	}, [4][]offset{
+		{{0, 0}},
+		{{0, 0}},
+		{{0, 0}},
+		{{0, 0}},
+	})
+
+	// SRS doesn't define 180 degree kicks, these follow the commonly used SRS+ table
+	flipKicks = [4][]offset{
+		{{0, 0}, {0, -1}, {1, -1}, {-1, -1}, {1, 0}, {-1, 0}},   // 0 -> 2
+		{{0, 0}, {1, 0}, {1, -2}, {1, -1}, {0, -2}, {0, -1}},    // R -> L
+		{{0, 0}, {0, 1}, {-1, 1}, {1, 1}, {-1, 0}, {1, 0}},      // 2 -> 0
+		{{0, 0}, {-1, 0}, {-1, -2}, {-1, -1}, {0, -2}, {0, -1}}, // L -> R
+	}
+)
+
+// newKickTable creates a kick table from the clockwise and 180 degree kicks.
+// The counter-clockwise kicks are derived from the clockwise ones, since rotating
+// counter-clockwise out of a state is the reverse of rotating clockwise into it.
+func newKickTable(right, flip [4][]offset) kickTable {
+	table := kickTable{right: right, flip: flip}
+	for from := range table.left {
+		reverse := right[(from+3)%4]
+		table.left[from] = make([]offset, len(reverse))
+		for i, kick := range reverse {
+			table.left[from][i] = offset{x: -kick.x, y: -kick.y}
+		}
+	}
+	return table
+}
@@ -0,0 +1,264 @@
+package tetris
+
+import (
+	"testing"
+)
+
+func TestTetro_RotateWallKicks(t *testing.T) {
+	tests := []struct {
+		name             string
+		boardHeight      int
+		boardWidth       int
+		stationaryBlocks []Block
+		setupTetro       func() *tetro
+		expected         bool
+		expectedRotation int
+		expectedCoords   []struct{ x, y int }
+	}{
+		{
+			name:        "no kick needed in open space",
+			boardHeight: 20,
+			boardWidth:  10,
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 5}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 1,
+			expectedCoords:   []struct{ x, y int }{{5, 5}, {5, 4}, {5, 6}, {6, 5}},
+		},
+		{
+			name:        "T kicks right off the left wall",
+			boardHeight: 20,
+			boardWidth:  10,
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 0, SpawnY: 5}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				tetro.setRotation(1)
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{1, 5}, {2, 5}, {0, 5}, {1, 6}},
+		},
+		{
+			name:        "T kicks up off the floor",
+			boardHeight: 20,
+			boardWidth:  10,
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 19}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 1,
+			expectedCoords:   []struct{ x, y int }{{4, 18}, {4, 17}, {4, 19}, {5, 18}},
+		},
+		{
+			name:        "I kicks left off the right wall",
+			boardHeight: 20,
+			boardWidth:  10,
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 8, SpawnY: 5}), ShapeI, &iStates, &iKicks, BlockStyles{})
+				tetro.setRotation(1)
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{8, 6}, {7, 6}, {6, 6}, {9, 6}},
+		},
+		{
+			name:        "I uses a later kick when earlier ones are blocked",
+			boardHeight: 20,
+			boardWidth:  10,
+			stationaryBlocks: []Block{
+				{x: 6, y: 4}, {x: 4, y: 4},
+			},
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 5}), ShapeI, &iStates, &iKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 1,
+			expectedCoords:   []struct{ x, y int }{{7, 5}, {7, 6}, {7, 7}, {7, 4}},
+		},
+		{
+			name:        "O never kicks",
+			boardHeight: 20,
+			boardWidth:  10,
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 8, SpawnY: 18}), ShapeO, &oStates, &oKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 1,
+			expectedCoords:   []struct{ x, y int }{{9, 18}, {8, 18}, {8, 19}, {9, 19}},
+		},
+		{
+			name:        "rotation fails when every kick is blocked",
+			boardHeight: 20,
+			boardWidth:  3,
+			stationaryBlocks: []Block{
+				{x: 1, y: 17}, {x: 2, y: 17},
+			},
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 1, SpawnY: 18}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				tetro.setRotation(2)
+				return &tetro
+			},
+			expected:         false,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{1, 18}, {2, 18}, {0, 18}, {1, 19}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tetro := tt.setupTetro()
+
+			if result := tetro.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)); result != tt.expected {
+				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
+			}
+
+			if result := tetro.Rotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)); result != tt.expected {
+				t.Errorf("Rotate() = %v, want %v", result, tt.expected)
+			}
+
+			if tetro.rotation != tt.expectedRotation {
+				t.Errorf("Rotate() rotation = %d, want %d", tetro.rotation, tt.expectedRotation)
+			}
+
+			for i, block := range tetro.Blocks() {
+				x, y := block.Coordinates()
+				if x != tt.expectedCoords[i].x || y != tt.expectedCoords[i].y {
+					t.Errorf("Block %d: got (%d,%d), want (%d,%d)", i, x, y, tt.expectedCoords[i].x, tt.expectedCoords[i].y)
+				}
+			}
+		})
+	}
+}
+
+func TestTetro_RotateLeftAndRotate180WallKicks(t *testing.T) {
+	tests := []struct {
+		name             string
+		direction        string
+		setupTetro       func() *tetro
+		expected         bool
+		expectedRotation int
+		expectedCoords   []struct{ x, y int }
+	}{
+		{
+			name:      "rotate left in open space",
+			direction: "left",
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 5}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 3,
+			expectedCoords:   []struct{ x, y int }{{5, 5}, {5, 6}, {5, 4}, {4, 5}},
+		},
+		{
+			name:      "rotate left kicks off the right wall",
+			direction: "left",
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 9, SpawnY: 5}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				tetro.setRotation(3)
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{8, 5}, {9, 5}, {7, 5}, {8, 6}},
+		},
+		{
+			name:      "rotate left derives the I kicks from the clockwise table",
+			direction: "left",
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: -1, SpawnY: 5}), ShapeI, &iStates, &iKicks, BlockStyles{})
+				tetro.setRotation(1)
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 0,
+			expectedCoords:   []struct{ x, y int }{{1, 5}, {2, 5}, {3, 5}, {0, 5}},
+		},
+		{
+			name:      "rotate 180 in open space",
+			direction: "180",
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 5}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{5, 5}, {6, 5}, {4, 5}, {5, 6}},
+		},
+		{
+			name:      "rotate 180 kicks up off the floor",
+			direction: "180",
+			setupTetro: func() *tetro {
+				tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 19}), ShapeT, &tStates, &jlstzKicks, BlockStyles{})
+				return &tetro
+			},
+			expected:         true,
+			expectedRotation: 2,
+			expectedCoords:   []struct{ x, y int }{{5, 18}, {6, 18}, {4, 18}, {5, 19}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tetro := tt.setupTetro()
+
+			var canRotate, rotated bool
+			switch tt.direction {
+			case "left":
+				canRotate = tetro.CanRotateLeft(NewBoard(20, 10))
+				rotated = tetro.RotateLeft(NewBoard(20, 10))
+			case "180":
+				canRotate = tetro.CanRotate180(NewBoard(20, 10))
+				rotated = tetro.Rotate180(NewBoard(20, 10))
+			}
+
+			if canRotate != tt.expected {
+				t.Errorf("CanRotate%s() = %v, want %v", tt.direction, canRotate, tt.expected)
+			}
+			if rotated != tt.expected {
+				t.Errorf("Rotate%s() = %v, want %v", tt.direction, rotated, tt.expected)
+			}
+			if tetro.rotation != tt.expectedRotation {
+				t.Errorf("rotation = %d, want %d", tetro.rotation, tt.expectedRotation)
+			}
+
+			for i, block := range tetro.Blocks() {
+				x, y := block.Coordinates()
+				if x != tt.expectedCoords[i].x || y != tt.expectedCoords[i].y {
+					t.Errorf("Block %d: got (%d,%d), want (%d,%d)", i, x, y, tt.expectedCoords[i].x, tt.expectedCoords[i].y)
+				}
+			}
+		})
+	}
+}
+
+func TestTetro_RotateLeftUndoesRotate(t *testing.T) {
+	shapes := map[Shape]*rotationStates{ShapeI: &iStates, ShapeJ: &jStates, ShapeL: &lStates, ShapeO: &oStates, ShapeS: &sStates, ShapeT: &tStates, ShapeZ: &zStates}
+	for shape, states := range shapes {
+		tetro := newTetro(NewFactory(Config{SpawnX: 5, SpawnY: 5}), shape, states, &jlstzKicks, BlockStyles{})
+		original := tetro.Blocks()
+
+		for r := 0; r < 4; r++ {
+			tetro.Rotate(NewBoard(20, 10))
+			tetro.RotateLeft(NewBoard(20, 10))
+			tetro.Rotate(NewBoard(20, 10))
+		}
+
+		tetro.Rotate180(NewBoard(20, 10))
+		tetro.Rotate180(NewBoard(20, 10))
+
+		for i, block := range tetro.Blocks() {
+			x, y := block.Coordinates()
+			origX, origY := original[i].Coordinates()
+			if x != origX || y != origY {
+				t.Errorf("Block %d: got (%d,%d), want (%d,%d)", i, x, y, origX, origY)
+			}
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewI(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -17,15 +17,15 @@ func TestNewI(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	i := newI()
+	i := f.newI()
 
 	if i == nil {
-		t.Fatal("newI() returned nil")
+		t.Fatal("f.newI() returned nil")
 	}
 
 	blocks := i.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newI() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newI() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
@@ -42,7 +42,7 @@ func TestNewI(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleI.Block0, config.StyleI.Block1, config.StyleI.Block2, config.StyleI.Block3}
+	expectedStyles := []any{f.config.StyleI.Block0, f.config.StyleI.Block1, f.config.StyleI.Block2, f.config.StyleI.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewI(t *testing.T) {
 }
 
 func TestI_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -76,10 +76,10 @@ func TestI_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			i := newI()
+			i := f.newI()
 
 			for r := 0; r < tt.rotation; r++ {
-				i.Rotate()
+				i.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := i.Blocks()
@@ -89,9 +89,9 @@ func TestI_Rotate(t *testing.T) {
 
 			expectedCoords := [][]struct{ x, y int }{
 				{{5, 5}, {6, 5}, {7, 5}, {4, 5}}, // initial state
-				{{5, 5}, {5, 4}, {5, 3}, {5, 6}}, // 1 rotation
-				{{5, 5}, {4, 5}, {3, 5}, {6, 5}}, // 2 rotations
-				{{5, 5}, {5, 6}, {5, 7}, {5, 4}}, // 3 rotations
+				{{6, 5}, {6, 6}, {6, 7}, {6, 4}}, // 1 rotation
+				{{6, 6}, {5, 6}, {4, 6}, {7, 6}}, // 2 rotations
+				{{5, 6}, {5, 5}, {5, 4}, {5, 7}}, // 3 rotations
 				{{5, 5}, {6, 5}, {7, 5}, {4, 5}}, // 4 rotations (back to initial)
 			}
 
@@ -107,7 +107,7 @@ func TestI_Rotate(t *testing.T) {
 }
 
 func TestI_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -133,7 +133,7 @@ func TestI_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupI:           func() Tetro { return newI() },
+			setupI:           func() Tetro { return f.newI() },
 		},
 		{
 			name:             "can rotate at left edge (implementation allows)",
@@ -142,7 +142,7 @@ func TestI_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupI: func() Tetro {
-				Init(Config{
+				f := NewFactory(Config{
 					SpawnX: 1,
 					SpawnY: 5,
 					StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
@@ -153,7 +153,7 @@ func TestI_CanRotate(t *testing.T) {
 					StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
 					StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 				})
-				return newI()
+				return f.newI()
 			},
 		},
 		{
@@ -163,7 +163,7 @@ func TestI_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupI: func() Tetro {
-				Init(Config{
+				f := NewFactory(Config{
 					SpawnX: 5,
 					SpawnY: 1,
 					StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
@@ -174,7 +174,7 @@ func TestI_CanRotate(t *testing.T) {
 					StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
 					StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 				})
-				return newI()
+				return f.newI()
 			},
 		},
 		{
@@ -185,14 +185,14 @@ func TestI_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupI:   func() Tetro { return newI() },
+			setupI:   func() Tetro { return f.newI() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := tt.setupI()
-			result := i.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := i.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
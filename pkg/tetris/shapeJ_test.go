@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewJ(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
@@ -17,19 +17,19 @@ func TestNewJ(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	j := newJ()
+	j := f.newJ()
 
 	if j == nil {
-		t.Fatal("newJ() returned nil")
+		t.Fatal("f.newJ() returned nil")
 	}
 
 	blocks := j.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newJ() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newJ() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
-		{5, 5}, {5, 4}, {5, 3}, {4, 5},
+		{4, 5}, {5, 5}, {6, 5}, {4, 4},
 	}
 
 	for i, expected := range expectedInitialCoords {
@@ -39,7 +39,7 @@ func TestNewJ(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleJ.Block0, config.StyleJ.Block1, config.StyleJ.Block2, config.StyleJ.Block3}
+	expectedStyles := []any{f.config.StyleJ.Block0, f.config.StyleJ.Block1, f.config.StyleJ.Block2, f.config.StyleJ.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -48,7 +48,7 @@ func TestNewJ(t *testing.T) {
 }
 
 func TestJ_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
@@ -60,7 +60,7 @@ func TestJ_Rotate(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	j := newJ()
+	j := f.newJ()
 
 	tests := []struct {
 		name     string
@@ -75,9 +75,9 @@ func TestJ_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			j = newJ()
+			j = f.newJ()
 			for r := 0; r < tt.rotation; r++ {
-				j.Rotate()
+				j.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := j.Blocks()
@@ -86,11 +86,11 @@ func TestJ_Rotate(t *testing.T) {
 			}
 
 			expectedCoords := [][]struct{ x, y int }{
-				{{5, 5}, {5, 4}, {5, 3}, {4, 5}}, // initial state
-				{{5, 5}, {4, 5}, {3, 5}, {5, 6}}, // 1 rotation
-				{{5, 5}, {5, 6}, {5, 7}, {6, 5}}, // 2 rotations
-				{{5, 5}, {6, 5}, {7, 5}, {5, 4}}, // 3 rotations
-				{{5, 5}, {5, 4}, {5, 3}, {4, 5}}, // 4 rotations (back to initial)
+				{{4, 5}, {5, 5}, {6, 5}, {4, 4}}, // initial state
+				{{5, 4}, {5, 5}, {5, 6}, {6, 4}}, // 1 rotation
+				{{6, 5}, {5, 5}, {4, 5}, {6, 6}}, // 2 rotations
+				{{5, 6}, {5, 5}, {5, 4}, {4, 6}}, // 3 rotations
+				{{4, 5}, {5, 5}, {6, 5}, {4, 4}}, // 4 rotations (back to initial)
 			}
 
 			expected := expectedCoords[tt.rotation]
@@ -105,7 +105,7 @@ func TestJ_Rotate(t *testing.T) {
 }
 
 func TestJ_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
@@ -131,7 +131,7 @@ func TestJ_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupJ:           func() Tetro { return newJ() },
+			setupJ:           func() Tetro { return f.newJ() },
 		},
 		{
 			name:             "can rotate with space",
@@ -140,7 +140,7 @@ func TestJ_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupJ: func() Tetro {
-				j := newJ()
+				j := f.newJ()
 				j.MoveRight()
 				return j
 			},
@@ -153,14 +153,14 @@ func TestJ_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupJ:   func() Tetro { return newJ() },
+			setupJ:   func() Tetro { return f.newJ() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			j := tt.setupJ()
-			result := j.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := j.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
@@ -2,50 +2,22 @@ package tetris
 
 type l struct{ tetro }
 
-func newL() Tetro {
-	x, y := config.SpawnX, config.SpawnY
-	s0, s1, s2, s3 := config.StyleL.Block0, config.StyleL.Block1, config.StyleL.Block2, config.StyleL.Block3
-
-	// 22
-	// 11
-	// 0033
-	return &l{
-		tetro: tetro{
-			x:      x,
-			y:      y,
-			block0: Block{x: x, y: y, style: s0},
-			block1: Block{x: x, y: y - 1, style: s1},
-			block2: Block{x: x, y: y - 2, style: s2},
-			block3: Block{x: x + 1, y: y, style: s3},
-		},
-	}
+// lStates holds the L shape in each rotation state, from spawn clockwise:
+//
+//	    33     22                 3300
+//	221100     11       001122       11
+//	           0033     33           22
+var lStates = rotationStates{
+	{{1, 0}, {0, 0}, {-1, 0}, {1, -1}},
+	{{0, 1}, {0, 0}, {0, -1}, {1, 1}},
+	{{-1, 0}, {0, 0}, {1, 0}, {-1, 1}},
+	{{0, -1}, {0, 0}, {0, 1}, {-1, -1}},
 }
 
-func (l *l) Rotate() {
-	switch l.rotation {
-	case 0:
-		l.block0.x, l.block0.y = l.x, l.y   //     33
-		l.block1.x, l.block1.y = l.x-1, l.y // 221100
-		l.block2.x, l.block2.y = l.x-2, l.y
-		l.block3.x, l.block3.y = l.x, l.y-1
-	case 1:
-		l.block0.x, l.block0.y = l.x, l.y   // 3300
-		l.block1.x, l.block1.y = l.x, l.y+1 //   11
-		l.block2.x, l.block2.y = l.x, l.y+2 //   22
-		l.block3.x, l.block3.y = l.x-1, l.y
-	case 2:
-		l.block0.x, l.block0.y = l.x, l.y   // 001122
-		l.block1.x, l.block1.y = l.x+1, l.y // 33
-		l.block2.x, l.block2.y = l.x+2, l.y
-		l.block3.x, l.block3.y = l.x, l.y+1
-	case 3:
-		l.block0.x, l.block0.y = l.x, l.y   // 22
-		l.block1.x, l.block1.y = l.x, l.y-1 // 11
-		l.block2.x, l.block2.y = l.x, l.y-2 // 0033
-		l.block3.x, l.block3.y = l.x+1, l.y
-	default:
+func (f *Factory) newL() Tetro {
+	return &l{
+		tetro: newTetro(f, ShapeL, &lStates, &jlstzKicks, f.config.StyleL),
 	}
-	l.updateRotation()
 }
 
 func (original *l) clone() Tetro {
@@ -55,6 +27,6 @@ func (original *l) clone() Tetro {
 	return clone
 }
 
-func (l *l) CanRotate(boardHeight, boardWidth int, stationaryBlocks []Block) bool {
-	return canRotate(l, boardHeight, boardWidth, stationaryBlocks) // implemented in tetris.go
+func (l *l) Spawn() Tetro {
+	return l.factory.newL()
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewL(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
@@ -17,22 +17,22 @@ func TestNewL(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	l := newL()
+	l := f.newL()
 
 	if l == nil {
-		t.Fatal("newL() returned nil")
+		t.Fatal("f.newL() returned nil")
 	}
 
 	blocks := l.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newL() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newL() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
-		{5, 5}, // block0: spawn position
-		{5, 4}, // block1: y - 1
-		{5, 3}, // block2: y - 2
-		{6, 5}, // block3: x + 1
+		{6, 5}, // block0: x + 1
+		{5, 5}, // block1: spawn position
+		{4, 5}, // block2: x - 1
+		{6, 4}, // block3: x + 1, y - 1
 	}
 
 	for i, expected := range expectedInitialCoords {
@@ -42,7 +42,7 @@ func TestNewL(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleL.Block0, config.StyleL.Block1, config.StyleL.Block2, config.StyleL.Block3}
+	expectedStyles := []any{f.config.StyleL.Block0, f.config.StyleL.Block1, f.config.StyleL.Block2, f.config.StyleL.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewL(t *testing.T) {
 }
 
 func TestL_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
@@ -63,7 +63,7 @@ func TestL_Rotate(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	l := newL()
+	l := f.newL()
 
 	tests := []struct {
 		name     string
@@ -78,9 +78,9 @@ func TestL_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			l = newL()
+			l = f.newL()
 			for r := 0; r < tt.rotation; r++ {
-				l.Rotate()
+				l.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := l.Blocks()
@@ -89,11 +89,11 @@ func TestL_Rotate(t *testing.T) {
 			}
 
 			expectedCoords := [][]struct{ x, y int }{
-				{{5, 5}, {5, 4}, {5, 3}, {6, 5}}, // rotation 0
-				{{5, 5}, {4, 5}, {3, 5}, {5, 4}}, // rotation 1
-				{{5, 5}, {5, 6}, {5, 7}, {4, 5}}, // rotation 2
-				{{5, 5}, {6, 5}, {7, 5}, {5, 6}}, // rotation 3
-				{{5, 5}, {5, 4}, {5, 3}, {6, 5}}, // rotation 4 (back to original)
+				{{6, 5}, {5, 5}, {4, 5}, {6, 4}}, // rotation 0
+				{{5, 6}, {5, 5}, {5, 4}, {6, 6}}, // rotation 1
+				{{4, 5}, {5, 5}, {6, 5}, {4, 6}}, // rotation 2
+				{{5, 4}, {5, 5}, {5, 6}, {4, 4}}, // rotation 3
+				{{6, 5}, {5, 5}, {4, 5}, {6, 4}}, // rotation 4 (back to original)
 			}
 
 			expected := expectedCoords[tt.rotation]
@@ -108,7 +108,7 @@ func TestL_Rotate(t *testing.T) {
 }
 
 func TestL_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
@@ -134,7 +134,7 @@ func TestL_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupL:           func() Tetro { return newL() },
+			setupL:           func() Tetro { return f.newL() },
 		},
 		{
 			name:             "can rotate with space",
@@ -143,7 +143,7 @@ func TestL_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupL: func() Tetro {
-				l := newL()
+				l := f.newL()
 				l.MoveRight()
 				return l
 			},
@@ -156,14 +156,14 @@ func TestL_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupL:   func() Tetro { return newL() },
+			setupL:   func() Tetro { return f.newL() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			l := tt.setupL()
-			result := l.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := l.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewO(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -17,15 +17,15 @@ func TestNewO(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	o := newO()
+	o := f.newO()
 
 	if o == nil {
-		t.Fatal("newO() returned nil")
+		t.Fatal("f.newO() returned nil")
 	}
 
 	blocks := o.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newO() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newO() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
@@ -42,7 +42,7 @@ func TestNewO(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleO.Block0, config.StyleO.Block1, config.StyleO.Block2, config.StyleO.Block3}
+	expectedStyles := []any{f.config.StyleO.Block0, f.config.StyleO.Block1, f.config.StyleO.Block2, f.config.StyleO.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewO(t *testing.T) {
 }
 
 func TestO_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -76,9 +76,9 @@ func TestO_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			o := newO()
+			o := f.newO()
 			for r := 0; r < tt.rotation; r++ {
-				o.Rotate()
+				o.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := o.Blocks()
@@ -88,9 +88,9 @@ func TestO_Rotate(t *testing.T) {
 
 			expectedCoords := [][]struct{ x, y int }{
 				{{5, 0}, {5, 1}, {6, 1}, {6, 0}}, // initial state
-				{{5, 1}, {6, 1}, {6, 0}, {5, 0}}, // 1 rotation
+				{{6, 0}, {5, 0}, {5, 1}, {6, 1}}, // 1 rotation
 				{{6, 1}, {6, 0}, {5, 0}, {5, 1}}, // 2 rotations
-				{{6, 0}, {5, 0}, {5, 1}, {6, 1}}, // 3 rotations
+				{{5, 1}, {6, 1}, {6, 0}, {5, 0}}, // 3 rotations
 				{{5, 0}, {5, 1}, {6, 1}, {6, 0}}, // 4 rotations (back to original)
 			}
 
@@ -106,7 +106,7 @@ func TestO_Rotate(t *testing.T) {
 }
 
 func TestO_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -132,7 +132,7 @@ func TestO_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupO:           func() Tetro { return newO() },
+			setupO:           func() Tetro { return f.newO() },
 		},
 		{
 			name:             "can rotate at edges (O shape fits in 2x2)",
@@ -141,7 +141,7 @@ func TestO_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupO: func() Tetro {
-				Init(Config{
+				f := NewFactory(Config{
 					SpawnX: 8,
 					SpawnY: 18,
 					StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -152,7 +152,7 @@ func TestO_CanRotate(t *testing.T) {
 					StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
 					StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 				})
-				return newO()
+				return f.newO()
 			},
 		},
 		{
@@ -163,7 +163,7 @@ func TestO_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupO:   func() Tetro { return newO() },
+			setupO:   func() Tetro { return f.newO() },
 		},
 		{
 			name:        "can always rotate - O piece stays in same 2x2 grid",
@@ -176,14 +176,14 @@ func TestO_CanRotate(t *testing.T) {
 				{x: 5, y: 7, style: "block"},
 			},
 			expected: true,
-			setupO:   func() Tetro { return newO() },
+			setupO:   func() Tetro { return f.newO() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			o := tt.setupO()
-			result := o.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := o.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewS(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
@@ -17,15 +17,15 @@ func TestNewS(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	s := newS()
+	s := f.newS()
 
 	if s == nil {
-		t.Fatal("newS() returned nil")
+		t.Fatal("f.newS() returned nil")
 	}
 
 	blocks := s.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newS() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newS() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
@@ -42,7 +42,7 @@ func TestNewS(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleS.Block0, config.StyleS.Block1, config.StyleS.Block2, config.StyleS.Block3}
+	expectedStyles := []any{f.config.StyleS.Block0, f.config.StyleS.Block1, f.config.StyleS.Block2, f.config.StyleS.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewS(t *testing.T) {
 }
 
 func TestS_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
@@ -63,7 +63,7 @@ func TestS_Rotate(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	s := newS()
+	s := f.newS()
 
 	tests := []struct {
 		name     string
@@ -78,9 +78,9 @@ func TestS_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s = newS()
+			s = f.newS()
 			for r := 0; r < tt.rotation; r++ {
-				s.Rotate()
+				s.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := s.Blocks()
@@ -90,9 +90,9 @@ func TestS_Rotate(t *testing.T) {
 
 			expectedCoords := [][]struct{ x, y int }{
 				{{5, 5}, {6, 5}, {5, 6}, {4, 6}}, // rotation 0
-				{{5, 5}, {5, 4}, {6, 5}, {6, 6}}, // rotation 1
-				{{5, 5}, {4, 5}, {5, 4}, {6, 4}}, // rotation 2
-				{{5, 5}, {5, 6}, {4, 5}, {4, 4}}, // rotation 3
+				{{6, 6}, {6, 7}, {5, 6}, {5, 5}}, // rotation 1
+				{{5, 7}, {4, 7}, {5, 6}, {6, 6}}, // rotation 2
+				{{4, 6}, {4, 5}, {5, 6}, {5, 7}}, // rotation 3
 				{{5, 5}, {6, 5}, {5, 6}, {4, 6}}, // rotation 4 (back to original)
 			}
 
@@ -108,7 +108,7 @@ func TestS_Rotate(t *testing.T) {
 }
 
 func TestS_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
@@ -134,7 +134,7 @@ func TestS_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupS:           func() Tetro { return newS() },
+			setupS:           func() Tetro { return f.newS() },
 		},
 		{
 			name:             "can rotate with space",
@@ -143,7 +143,7 @@ func TestS_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupS: func() Tetro {
-				s := newS()
+				s := f.newS()
 				s.MoveRight()
 				return s
 			},
@@ -156,14 +156,14 @@ func TestS_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupS:   func() Tetro { return newS() },
+			setupS:   func() Tetro { return f.newS() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := tt.setupS()
-			result := s.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := s.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
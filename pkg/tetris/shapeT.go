@@ -2,50 +2,22 @@ package tetris
 
 type t struct{ tetro }
 
-func newT() Tetro {
-	x, y := config.SpawnX, config.SpawnY
-	s0, s1, s2, s3 := config.StyleT.Block0, config.StyleT.Block1, config.StyleT.Block2, config.StyleT.Block3
-
-	//   33
-	// 110022
-	return &t{
-		tetro: tetro{
-			x:      x,
-			y:      y,
-			block0: Block{x: x, y: y, style: s0},
-			block1: Block{x: x - 1, y: y, style: s1},
-			block2: Block{x: x + 1, y: y, style: s2},
-			block3: Block{x: x, y: y - 1, style: s3},
-		},
-	}
+// tStates holds the T shape in each rotation state, from spawn clockwise:
+//
+//	  33       11                   22
+//	110022     0033     220011     3300
+//	           22         33         11
+var tStates = rotationStates{
+	{{0, 0}, {-1, 0}, {1, 0}, {0, -1}},
+	{{0, 0}, {0, -1}, {0, 1}, {1, 0}},
+	{{0, 0}, {1, 0}, {-1, 0}, {0, 1}},
+	{{0, 0}, {0, 1}, {0, -1}, {-1, 0}},
 }
 
-func (t *t) Rotate() {
-	switch t.rotation {
-	case 0:
-		t.block0.x, t.block0.y = t.x, t.y   //   22
-		t.block1.x, t.block1.y = t.x, t.y+1 // 3300
-		t.block2.x, t.block2.y = t.x, t.y-1 //   11
-		t.block3.x, t.block3.y = t.x-1, t.y
-	case 1:
-		t.block0.x, t.block0.y = t.x, t.y   // 220011
-		t.block1.x, t.block1.y = t.x+1, t.y //   33
-		t.block2.x, t.block2.y = t.x-1, t.y
-		t.block3.x, t.block3.y = t.x, t.y+1
-	case 2:
-		t.block0.x, t.block0.y = t.x, t.y   // 11
-		t.block1.x, t.block1.y = t.x, t.y-1 // 0033
-		t.block2.x, t.block2.y = t.x, t.y+1 // 22
-		t.block3.x, t.block3.y = t.x+1, t.y
-
-	case 3:
-		t.block0.x, t.block0.y = t.x, t.y   //   33
-		t.block1.x, t.block1.y = t.x-1, t.y // 110022
-		t.block2.x, t.block2.y = t.x+1, t.y
-		t.block3.x, t.block3.y = t.x, t.y-1
-	default:
+func (f *Factory) newT() Tetro {
+	return &t{
+		tetro: newTetro(f, ShapeT, &tStates, &jlstzKicks, f.config.StyleT),
 	}
-	t.updateRotation()
 }
 
 func (original *t) clone() Tetro {
@@ -55,6 +27,35 @@ func (original *t) clone() Tetro {
 	return clone
 }
 
-func (t *t) CanRotate(boardHeight, boardWidth int, stationaryBlocks []Block) bool {
-	return canRotate(t, boardHeight, boardWidth, stationaryBlocks) // implemented in tetris.go
+func (t *t) Spawn() Tetro {
+	return t.factory.newT()
+}
+
+// tCorners are the 4 corners diagonal to the centre of the T, clockwise from the top left.
+// The 2 front corners, either side of the point of the T, are the corners at the rotation state and the one after it.
+var tCorners = [4]offset{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+
+// Spin follows the 3 corner rule. If 3 of the 4 corners around the centre of the T are filled, or are walls or floor,
+// it's a T-spin. If only 1 of the front corners is filled it's a mini T-spin, unless the rotation needed one of the
+// furthest kicks (1 across and 2 up or down) to get there, in which case it counts as a full T-spin.
+func (t *t) Spin(board *Board) Spin {
+	filled := [4]bool{}
+	count := 0
+	for i, corner := range tCorners {
+		filled[i] = !board.Free(t.x+corner.x, t.y+corner.y)
+		if filled[i] {
+			count++
+		}
+	}
+	if count < 3 {
+		return NoSpin
+	}
+
+	if filled[t.rotation] && filled[(t.rotation+1)%4] {
+		return FullSpin
+	}
+	if (t.kicked.x == 1 || t.kicked.x == -1) && (t.kicked.y == 2 || t.kicked.y == -2) {
+		return FullSpin
+	}
+	return MiniSpin
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewT(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
@@ -17,15 +17,15 @@ func TestNewT(t *testing.T) {
 		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
 	})
 
-	t_piece := newT()
+	t_piece := f.newT()
 
 	if t_piece == nil {
-		t.Fatal("newT() returned nil")
+		t.Fatal("f.newT() returned nil")
 	}
 
 	blocks := t_piece.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newT() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newT() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
@@ -42,7 +42,7 @@ func TestNewT(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleT.Block0, config.StyleT.Block1, config.StyleT.Block2, config.StyleT.Block3}
+	expectedStyles := []any{f.config.StyleT.Block0, f.config.StyleT.Block1, f.config.StyleT.Block2, f.config.StyleT.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewT(t *testing.T) {
 }
 
 func TestT_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
@@ -76,9 +76,9 @@ func TestT_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t_piece := newT()
+			t_piece := f.newT()
 			for r := 0; r < tt.rotation; r++ {
-				t_piece.Rotate()
+				t_piece.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := t_piece.Blocks()
@@ -88,9 +88,9 @@ func TestT_Rotate(t *testing.T) {
 
 			expectedCoords := [][]struct{ x, y int }{
 				{{5, 5}, {4, 5}, {6, 5}, {5, 4}}, // rotation 0
-				{{5, 5}, {5, 6}, {5, 4}, {4, 5}}, // rotation 1
+				{{5, 5}, {5, 4}, {5, 6}, {6, 5}}, // rotation 1
 				{{5, 5}, {6, 5}, {4, 5}, {5, 6}}, // rotation 2
-				{{5, 5}, {5, 4}, {5, 6}, {6, 5}}, // rotation 3
+				{{5, 5}, {5, 6}, {5, 4}, {4, 5}}, // rotation 3
 				{{5, 5}, {4, 5}, {6, 5}, {5, 4}}, // rotation 4 (back to original)
 			}
 
@@ -106,7 +106,7 @@ func TestT_Rotate(t *testing.T) {
 }
 
 func TestT_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
@@ -132,7 +132,7 @@ func TestT_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupT:           func() Tetro { return newT() },
+			setupT:           func() Tetro { return f.newT() },
 		},
 		{
 			name:             "can rotate with space",
@@ -141,7 +141,7 @@ func TestT_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupT: func() Tetro {
-				t_piece := newT()
+				t_piece := f.newT()
 				t_piece.MoveRight()
 				return t_piece
 			},
@@ -154,17 +154,140 @@ func TestT_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupT:   func() Tetro { return newT() },
+			setupT:   func() Tetro { return f.newT() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t_piece := tt.setupT()
-			result := t_piece.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := t_piece.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
 }
+
+// asT returns the tetro as a T shape, or nil if it's another shape
+func asT(tetro Tetro) *t {
+	tPiece, _ := tetro.(*t)
+	return tPiece
+}
+
+func TestT_Spin(t *testing.T) {
+	tests := []struct {
+		name             string
+		x, y             int
+		rotation         int
+		kicked           offset
+		stationaryBlocks []Block
+		expected         Spin
+	}{
+		{
+			name:     "no corners filled",
+			x:        5,
+			y:        10,
+			rotation: 2,
+			expected: NoSpin,
+		},
+		{
+			name:     "only 2 corners filled",
+			x:        5,
+			y:        10,
+			rotation: 2,
+			stationaryBlocks: []Block{
+				{x: 4, y: 11}, {x: 6, y: 11},
+			},
+			expected: NoSpin,
+		},
+		{
+			name:     "3 corners with both front corners filled",
+			x:        5,
+			y:        10,
+			rotation: 2, // pointing down, so the front corners are below
+			stationaryBlocks: []Block{
+				{x: 4, y: 11}, {x: 6, y: 11}, {x: 4, y: 9},
+			},
+			expected: FullSpin,
+		},
+		{
+			name:     "3 corners with one front corner filled",
+			x:        5,
+			y:        10,
+			rotation: 0, // pointing up, so the front corners are above
+			stationaryBlocks: []Block{
+				{x: 4, y: 11}, {x: 6, y: 11}, {x: 4, y: 9},
+			},
+			expected: MiniSpin,
+		},
+		{
+			name:     "mini upgraded by the furthest kick",
+			x:        5,
+			y:        10,
+			rotation: 0,
+			kicked:   offset{-1, 2},
+			stationaryBlocks: []Block{
+				{x: 4, y: 11}, {x: 6, y: 11}, {x: 4, y: 9},
+			},
+			expected: FullSpin,
+		},
+		{
+			name:     "4 corners filled",
+			x:        5,
+			y:        10,
+			rotation: 1,
+			stationaryBlocks: []Block{
+				{x: 4, y: 9}, {x: 6, y: 9}, {x: 4, y: 11}, {x: 6, y: 11},
+			},
+			expected: FullSpin,
+		},
+		{
+			name:     "wall counts as filled corners",
+			x:        0,
+			y:        10,
+			rotation: 1, // pointing right, away from the wall
+			stationaryBlocks: []Block{
+				{x: 1, y: 11},
+			},
+			expected: MiniSpin,
+		},
+		{
+			name:     "floor counts as filled corners",
+			x:        5,
+			y:        19,
+			rotation: 2, // pointing down into the floor
+			stationaryBlocks: []Block{
+				{x: 4, y: 18},
+			},
+			expected: FullSpin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFactory(Config{SpawnX: tt.x, SpawnY: tt.y})
+			tPiece := asT(f.newT())
+			tPiece.setRotation(tt.rotation)
+			tPiece.kicked = tt.kicked
+
+			board := newTestBoard(20, 10, tt.stationaryBlocks)
+			if spin := tPiece.Spin(board); spin != tt.expected {
+				t.Errorf("Spin() = %v, want %v", spin, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTetro_SpinOnlyT(t *testing.T) {
+	board := NewBoard(20, 10)
+	for _, factory := range tetroFactories {
+		tetro := factory(NewFactory(Config{SpawnX: 5, SpawnY: 19}))
+		if asT(tetro) != nil {
+			continue
+		}
+		if spin := tetro.Spin(board); spin != NoSpin {
+			t.Errorf("%T Spin() = %v, want %v", tetro, spin, NoSpin)
+		}
+	}
+}
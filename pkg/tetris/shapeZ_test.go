@@ -5,7 +5,7 @@ import (
 )
 
 func TestNewZ(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
@@ -17,15 +17,15 @@ func TestNewZ(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	z := newZ()
+	z := f.newZ()
 
 	if z == nil {
-		t.Fatal("newZ() returned nil")
+		t.Fatal("f.newZ() returned nil")
 	}
 
 	blocks := z.Blocks()
 	if len(blocks) != 4 {
-		t.Errorf("newZ() should have 4 blocks, got %d", len(blocks))
+		t.Errorf("f.newZ() should have 4 blocks, got %d", len(blocks))
 	}
 
 	expectedInitialCoords := []struct{ x, y int }{
@@ -42,7 +42,7 @@ func TestNewZ(t *testing.T) {
 		}
 	}
 
-	expectedStyles := []any{config.StyleZ.Block0, config.StyleZ.Block1, config.StyleZ.Block2, config.StyleZ.Block3}
+	expectedStyles := []any{f.config.StyleZ.Block0, f.config.StyleZ.Block1, f.config.StyleZ.Block2, f.config.StyleZ.Block3}
 	for i, expected := range expectedStyles {
 		if blocks[i].Style() != expected {
 			t.Errorf("Block %d style = %v, want %v", i, blocks[i].Style(), expected)
@@ -51,7 +51,7 @@ func TestNewZ(t *testing.T) {
 }
 
 func TestZ_Rotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
@@ -63,7 +63,7 @@ func TestZ_Rotate(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	z := newZ()
+	z := f.newZ()
 
 	tests := []struct {
 		name     string
@@ -78,9 +78,9 @@ func TestZ_Rotate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			z = newZ()
+			z = f.newZ()
 			for r := 0; r < tt.rotation; r++ {
-				z.Rotate()
+				z.Rotate(NewBoard(20, 10))
 			}
 
 			blocks := z.Blocks()
@@ -90,9 +90,9 @@ func TestZ_Rotate(t *testing.T) {
 
 			expectedCoords := [][]struct{ x, y int }{
 				{{5, 5}, {4, 5}, {5, 6}, {6, 6}}, // rotation 0
-				{{5, 5}, {5, 6}, {6, 5}, {6, 4}}, // rotation 1
-				{{5, 5}, {6, 5}, {5, 4}, {4, 4}}, // rotation 2
-				{{5, 5}, {5, 4}, {4, 5}, {4, 6}}, // rotation 3
+				{{6, 6}, {6, 5}, {5, 6}, {5, 7}}, // rotation 1
+				{{5, 7}, {6, 7}, {5, 6}, {4, 6}}, // rotation 2
+				{{4, 6}, {4, 7}, {5, 6}, {5, 5}}, // rotation 3
 				{{5, 5}, {4, 5}, {5, 6}, {6, 6}}, // rotation 4 (back to original)
 			}
 
@@ -108,7 +108,7 @@ func TestZ_Rotate(t *testing.T) {
 }
 
 func TestZ_CanRotate(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 5,
 		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
@@ -134,7 +134,7 @@ func TestZ_CanRotate(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupZ:           func() Tetro { return newZ() },
+			setupZ:           func() Tetro { return f.newZ() },
 		},
 		{
 			name:             "can rotate with space",
@@ -143,7 +143,7 @@ func TestZ_CanRotate(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         true,
 			setupZ: func() Tetro {
-				z := newZ()
+				z := f.newZ()
 				z.MoveRight()
 				return z
 			},
@@ -156,14 +156,14 @@ func TestZ_CanRotate(t *testing.T) {
 				{x: 0, y: 0, style: "block"},
 			},
 			expected: true,
-			setupZ:   func() Tetro { return newZ() },
+			setupZ:   func() Tetro { return f.newZ() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			z := tt.setupZ()
-			result := z.CanRotate(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := z.CanRotate(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanRotate() = %v, want %v", result, tt.expected)
 			}
@@ -1,35 +1,108 @@
 package tetris
 
 import (
+	"fmt"
 	"math/rand"
 )
 
-var (
-	config         Config
-	tetroFactories = []func() Tetro{newO, newI, newS, newZ, newL, newJ, newT}
+var tetroFactories = []func(f *Factory) Tetro{
+	(*Factory).newO,
+	(*Factory).newI,
+	(*Factory).newS,
+	(*Factory).newZ,
+	(*Factory).newL,
+	(*Factory).newJ,
+	(*Factory).newT,
+}
+
+const (
+	ShapeO Shape = iota
+	ShapeI
+	ShapeS
+	ShapeZ
+	ShapeL
+	ShapeJ
+	ShapeT
 )
 
+// Shape is one of the 7 tetro shapes, in the same order as tetroFactories
+type Shape int
+
+var shapeNames = []string{"O", "I", "S", "Z", "L", "J", "T"}
+
+func (s Shape) String() string {
+	if s < ShapeO || s > ShapeT {
+		return fmt.Sprintf("Shape(%d)", int(s))
+	}
+	return shapeNames[s]
+}
+
+// ParseShape returns the shape for its letter, e.g. "T"
+func ParseShape(name string) (Shape, error) {
+	for shape, shapeName := range shapeNames {
+		if shapeName == name {
+			return Shape(shape), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown shape: %q", name)
+}
+
+// MarshalText saves a shape as its letter
+func (s Shape) MarshalText() ([]byte, error) {
+	if s < ShapeO || s > ShapeT {
+		return nil, fmt.Errorf("unknown shape: %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *Shape) UnmarshalText(text []byte) error {
+	shape, err := ParseShape(string(text))
+	if err != nil {
+		return err
+	}
+	*s = shape
+	return nil
+}
+
 type Tetro interface {
 	Blocks() []Block
 	MoveRight()
 	MoveLeft()
 	MoveDown()
-	Rotate()
-	CanMoveDown(boardHeight, boardWidth int, stationaryBlocks []Block) bool
-	CanMoveRight(boardHeight, boardWidth int, stationaryBlocks []Block) bool
-	CanMoveLeft(boardHeight, boardWidth int, stationaryBlocks []Block) bool
-	CanRotate(boardHeight, boardWidth int, stationaryBlocks []Block) bool
+	MoveUp() // e.g. when the stack is pushed up underneath the tetro
+	Rotate(board *Board) bool
+	RotateLeft(board *Board) bool
+	Rotate180(board *Board) bool
+	CanMoveDown(board *Board) bool
+	CanMoveRight(board *Board) bool
+	CanMoveLeft(board *Board) bool
+	CanRotate(board *Board) bool
+	CanRotateLeft(board *Board) bool
+	CanRotate180(board *Board) bool
+	Ghost(board *Board) []Block
+	Fits(board *Board) bool
+	Spin(board *Board) Spin // checks if the tetro is wedged in place by its last rotation, only the T shape can spin
+	Spawn() Tetro           // returns a new tetro of the same shape, at the spawn position and rotation
+	Shape() Shape
+	State() TetroState // returns everything needed to create the tetro again where it is, see Factory.RestoreTetro
 	clone() Tetro
+	restore(state TetroState)
 }
 
 type tetro struct {
-	x        int
-	y        int
-	rotation int
-	block0   Block
-	block1   Block
-	block2   Block
-	block3   Block
+	factory    *Factory
+	shape      Shape
+	x          int
+	y          int
+	rotation   int
+	kicked     offset // kick used by the last rotation
+	states     *rotationStates
+	kicks      *kickTable
+	ghostStyle any
+	block0     Block
+	block1     Block
+	block2     Block
+	block3     Block
 }
 
 type BlockStyles struct {
@@ -37,6 +110,7 @@ type BlockStyles struct {
 	Block1 any
 	Block2 any
 	Block3 any
+	Ghost  any // style for the blocks of the ghost, showing where the tetro will land
 }
 
 type Config struct {
@@ -49,14 +123,57 @@ type Config struct {
 	StyleL BlockStyles
 	StyleJ BlockStyles
 	StyleT BlockStyles
+	// StyleGarbage is the style of garbage blocks, rows added to the board that no tetro dropped, e.g. in a dig race
+	StyleGarbage any
 }
 
+// Init sets the config used by NewRandomTetro.
+//
+// Deprecated: the config is shared by everything in the process, use NewFactory to give each game its own.
 func Init(cfg Config) {
-	config = cfg
+	defaultFactory = NewFactory(cfg)
+}
+
+// NewRandomTetro creates a tetro of a random shape using the config set by Init.
+//
+// Deprecated: use Factory.NewRandomTetro.
+func NewRandomTetro(rng *rand.Rand) Tetro {
+	return defaultFactory.NewRandomTetro(rng)
 }
 
-func NewRandomTetro() Tetro {
-	return tetroFactories[rand.Intn(len(tetroFactories))]()
+// newTetro creates a tetro at the factory's spawn position in its spawn rotation state.
+// Each shape file provides its own rotation states and kick table.
+func newTetro(f *Factory, shape Shape, states *rotationStates, kicks *kickTable, styles BlockStyles) tetro {
+	t := tetro{
+		factory:    f,
+		shape:      shape,
+		x:          f.config.SpawnX,
+		y:          f.config.SpawnY,
+		states:     states,
+		kicks:      kicks,
+		ghostStyle: styles.Ghost,
+		block0:     Block{style: styles.Block0},
+		block1:     Block{style: styles.Block1},
+		block2:     Block{style: styles.Block2},
+		block3:     Block{style: styles.Block3},
+	}
+	t.setRotation(0)
+	return t
+}
+
+func (t *tetro) Shape() Shape {
+	return t.shape
+}
+
+func (t *tetro) State() TetroState {
+	return TetroState{
+		Shape:    t.shape,
+		X:        t.x,
+		Y:        t.y,
+		Rotation: t.rotation,
+		KickX:    t.kicked.x,
+		KickY:    t.kicked.y,
+	}
 }
 
 func (t *tetro) Blocks() []Block {
@@ -68,11 +185,21 @@ func (t *tetro) Blocks() []Block {
 	}
 }
 
-func (t *tetro) updateRotation() {
-	t.rotation++
-	if t.rotation > 3 {
-		t.rotation = 0
-	}
+// restore moves the tetro to the position and rotation of a saved state
+func (t *tetro) restore(state TetroState) {
+	t.x, t.y = state.X, state.Y
+	t.kicked = offset{x: state.KickX, y: state.KickY}
+	t.setRotation(state.Rotation)
+}
+
+// setRotation moves the blocks to the given rotation state, relative to the tetro's position
+func (t *tetro) setRotation(rotation int) {
+	state := t.states[rotation]
+	t.rotation = rotation
+	t.block0.x, t.block0.y = t.x+state[0].x, t.y+state[0].y
+	t.block1.x, t.block1.y = t.x+state[1].x, t.y+state[1].y
+	t.block2.x, t.block2.y = t.x+state[2].x, t.y+state[2].y
+	t.block3.x, t.block3.y = t.x+state[3].x, t.y+state[3].y
 }
 
 func (t *tetro) MoveRight() {
@@ -99,90 +226,169 @@ func (t *tetro) MoveDown() {
 	t.block3.MoveDown()
 }
 
-func (t *tetro) CanMoveDown(boardHeight, boardWidth int, stationaryBlocks []Block) bool {
+func (t *tetro) MoveUp() {
+	t.y--
+	t.block0.moveUp()
+	t.block1.moveUp()
+	t.block2.moveUp()
+	t.block3.moveUp()
+}
+
+// Fits checks the tetro doesn't overlap the walls, floor or any blocks on the board where it is
+func (t *tetro) Fits(board *Board) bool {
+	return t.fits(board)
+}
+
+func (t *tetro) CanMoveDown(board *Board) bool {
 	for _, block := range t.Blocks() {
 		x, y := block.Coordinates()
-		if y >= boardHeight-1 {
+		if !board.Free(x, y+1) {
 			return false
 		}
-
-		for _, fallenBlock := range stationaryBlocks {
-			fallenX, fallenY := fallenBlock.Coordinates()
-			if x == fallenX && y+1 == fallenY {
-				return false
-			}
-		}
-
 	}
 	return true
 }
 
-func (t *tetro) CanMoveRight(boardHeight, boardWidth int, stationaryBlocks []Block) bool {
+func (t *tetro) CanMoveRight(board *Board) bool {
 	for _, block := range t.Blocks() {
 		x, y := block.Coordinates()
-		if x >= boardWidth-1 {
+		if !board.Free(x+1, y) {
 			return false
 		}
-
-		for _, fallenBlock := range stationaryBlocks {
-			fallenX, fallenY := fallenBlock.Coordinates()
-			if x+1 == fallenX && y == fallenY {
-				return false
-			}
-		}
 	}
 	return true
 }
 
-func (t *tetro) CanMoveLeft(boardHeight, boardWidth int, stationaryBlocks []Block) bool {
+func (t *tetro) CanMoveLeft(board *Board) bool {
 	for _, block := range t.Blocks() {
 		x, y := block.Coordinates()
-		if x <= 0 {
+		if !board.Free(x-1, y) {
 			return false
 		}
-
-		for _, fallenBlock := range stationaryBlocks {
-			fallenX, fallenY := fallenBlock.Coordinates()
-			if x-1 == fallenX && y == fallenY {
-				return false
-			}
-		}
 	}
 	return true
 }
 
-// CanRotate and Clone methods are implemented in each shape file, as each shape has unique rotation logic.
-// These canRotate and clone functions are defined here to avoid some code duplication in the shapes.
-func canRotate(tetro Tetro, boardHeight, boardWidth int, stationaryBlocks []Block) bool {
-	clone := tetro.clone()
-	clone.Rotate()
-	for _, block := range clone.Blocks() {
-		x, y := block.Coordinates()
-		if x < 0 || x >= boardWidth || y >= boardHeight {
-			return false
+// Rotate turns the tetro clockwise using the Super Rotation System.
+// Each offset in the kick table is tried in order and the first one that fits is applied.
+// Returns false, leaving the tetro unchanged, if none of the offsets fit.
+func (t *tetro) Rotate(board *Board) bool {
+	return t.rotate((t.rotation+1)%4, t.kicks.right[t.rotation], board)
+}
+
+// RotateLeft turns the tetro counter-clockwise, kicking in the same way as Rotate
+func (t *tetro) RotateLeft(board *Board) bool {
+	return t.rotate((t.rotation+3)%4, t.kicks.left[t.rotation], board)
+}
+
+// Rotate180 turns the tetro through 180 degrees, kicking in the same way as Rotate
+func (t *tetro) Rotate180(board *Board) bool {
+	return t.rotate((t.rotation+2)%4, t.kicks.flip[t.rotation], board)
+}
+
+func (t *tetro) CanRotate(board *Board) bool {
+	_, ok := t.kick((t.rotation+1)%4, t.kicks.right[t.rotation], board)
+	return ok
+}
+
+func (t *tetro) CanRotateLeft(board *Board) bool {
+	_, ok := t.kick((t.rotation+3)%4, t.kicks.left[t.rotation], board)
+	return ok
+}
+
+func (t *tetro) CanRotate180(board *Board) bool {
+	_, ok := t.kick((t.rotation+2)%4, t.kicks.flip[t.rotation], board)
+	return ok
+}
+
+// rotate applies the first kick that allows the tetro to turn to the given rotation state
+func (t *tetro) rotate(rotation int, kicks []offset, board *Board) bool {
+	rotated, ok := t.kick(rotation, kicks, board)
+	if ok {
+		*t = rotated
+	}
+	return ok
+}
+
+// TetroState is where a tetro is and how it got there, everything needed to create it again, e.g. when resuming a saved game
+type TetroState struct {
+	Shape    Shape `json:"shape"`
+	X        int   `json:"x"`
+	Y        int   `json:"y"`
+	Rotation int   `json:"rotation"`
+	KickX    int   `json:"kickX"` // kick used by the last rotation, needed to tell a T-spin from a mini
+	KickY    int   `json:"kickY"`
+}
+
+// Spin is the kind of spin a tetro was rotated into place with
+type Spin int
+
+const (
+	NoSpin Spin = iota
+	MiniSpin
+	FullSpin
+)
+
+// Spin only applies to the T shape, which overrides it
+func (t *tetro) Spin(board *Board) Spin {
+	return NoSpin
+}
+
+// Ghost returns the blocks of the tetro at the lowest position it can drop to, in the ghost style
+func (t *tetro) Ghost(board *Board) []Block {
+	ghost := clone(*t)
+	for ghost.CanMoveDown(board) {
+		ghost.MoveDown()
+	}
+
+	blocks := ghost.Blocks()
+	for i := range blocks {
+		blocks[i].style = t.ghostStyle
+	}
+	return blocks
+}
+
+// kick returns a copy of the tetro in the given rotation state, moved by the first offset in kicks that fits
+func (t *tetro) kick(rotation int, kicks []offset, board *Board) (tetro, bool) {
+	for _, k := range kicks {
+		candidate := clone(*t)
+		candidate.x += k.x
+		candidate.y += k.y
+		candidate.setRotation(rotation)
+		candidate.kicked = k
+		if candidate.fits(board) {
+			return candidate, true
 		}
+	}
+	return tetro{}, false
+}
 
-		for _, fallenBlock := range stationaryBlocks {
-			fallenX, fallenY := fallenBlock.Coordinates()
-			if x == fallenX && y == fallenY {
-				return false
-			}
+// fits checks every block of the tetro is in a free position on the board
+func (t *tetro) fits(board *Board) bool {
+	for _, block := range t.Blocks() {
+		if !board.Free(block.Coordinates()) {
+			return false
 		}
 	}
 	return true
 }
 
 // Note that this private clone function returns a tetro type, not a Tetro interface.
-// The clone method defined in each shape returns an actual Tetro, which includes a Rotate method.
-// The Rotate method is used in canRotate above
+// The clone method defined in each shape returns an actual Tetro of that shape.
 func clone(original tetro) tetro {
 	return tetro{
-		x:        original.x,
-		y:        original.y,
-		rotation: original.rotation,
-		block0:   Block{x: original.block0.x, y: original.block0.y, style: original.block0.style},
-		block1:   Block{x: original.block1.x, y: original.block1.y, style: original.block1.style},
-		block2:   Block{x: original.block2.x, y: original.block2.y, style: original.block2.style},
-		block3:   Block{x: original.block3.x, y: original.block3.y, style: original.block3.style},
+		factory:    original.factory,
+		shape:      original.shape,
+		x:          original.x,
+		y:          original.y,
+		rotation:   original.rotation,
+		kicked:     original.kicked,
+		states:     original.states,
+		kicks:      original.kicks,
+		ghostStyle: original.ghostStyle,
+		block0:     Block{x: original.block0.x, y: original.block0.y, style: original.block0.style},
+		block1:     Block{x: original.block1.x, y: original.block1.y, style: original.block1.style},
+		block2:     Block{x: original.block2.x, y: original.block2.y, style: original.block2.style},
+		block3:     Block{x: original.block3.x, y: original.block3.y, style: original.block3.style},
 	}
 }
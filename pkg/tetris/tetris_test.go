@@ -1,6 +1,8 @@
 package tetris
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -19,14 +21,14 @@ func TestInit(t *testing.T) {
 
 	Init(testConfig)
 
-	if config.SpawnX != testConfig.SpawnX {
-		t.Errorf("Init() SpawnX = %v, want %v", config.SpawnX, testConfig.SpawnX)
+	if defaultFactory.config.SpawnX != testConfig.SpawnX {
+		t.Errorf("Init() SpawnX = %v, want %v", defaultFactory.config.SpawnX, testConfig.SpawnX)
 	}
-	if config.SpawnY != testConfig.SpawnY {
-		t.Errorf("Init() SpawnY = %v, want %v", config.SpawnY, testConfig.SpawnY)
+	if defaultFactory.config.SpawnY != testConfig.SpawnY {
+		t.Errorf("Init() SpawnY = %v, want %v", defaultFactory.config.SpawnY, testConfig.SpawnY)
 	}
-	if config.StyleO.Block0 != testConfig.StyleO.Block0 {
-		t.Errorf("Init() StyleO.Block0 = %v, want %v", config.StyleO.Block0, testConfig.StyleO.Block0)
+	if defaultFactory.config.StyleO.Block0 != testConfig.StyleO.Block0 {
+		t.Errorf("Init() StyleO.Block0 = %v, want %v", defaultFactory.config.StyleO.Block0, testConfig.StyleO.Block0)
 	}
 }
 
@@ -43,7 +45,7 @@ func TestNewRandomTetro(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	tetro := NewRandomTetro()
+	tetro := NewRandomTetro(rand.New(rand.NewSource(1)))
 	if tetro == nil {
 		t.Fatal("NewRandomTetro() returned nil")
 	}
@@ -65,7 +67,7 @@ func TestNewRandomTetro(t *testing.T) {
 }
 
 func TestTetro_MoveRight(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -77,7 +79,7 @@ func TestTetro_MoveRight(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	o := newO()
+	o := f.newO()
 	originalBlocks := o.Blocks()
 
 	o.MoveRight()
@@ -97,7 +99,7 @@ func TestTetro_MoveRight(t *testing.T) {
 }
 
 func TestTetro_MoveLeft(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -109,7 +111,7 @@ func TestTetro_MoveLeft(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	o := newO()
+	o := f.newO()
 	originalBlocks := o.Blocks()
 
 	o.MoveLeft()
@@ -129,7 +131,7 @@ func TestTetro_MoveLeft(t *testing.T) {
 }
 
 func TestTetro_MoveDown(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -141,7 +143,7 @@ func TestTetro_MoveDown(t *testing.T) {
 		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 	})
 
-	o := newO()
+	o := f.newO()
 	originalBlocks := o.Blocks()
 
 	o.MoveDown()
@@ -160,8 +162,30 @@ func TestTetro_MoveDown(t *testing.T) {
 	}
 }
 
+func TestTetro_MoveUp(t *testing.T) {
+	f := newStyledTestFactory()
+
+	o := f.newO()
+	originalBlocks := o.Blocks()
+
+	o.MoveUp()
+	newBlocks := o.Blocks()
+
+	for i := 0; i < 4; i++ {
+		origX, origY := originalBlocks[i].Coordinates()
+		newX, newY := newBlocks[i].Coordinates()
+
+		if newX != origX || newY != origY-1 {
+			t.Errorf("Block %d moved from (%d,%d) to (%d,%d), want (%d,%d)", i, origX, origY, newX, newY, origX, origY-1)
+		}
+	}
+	if state := o.State(); state.Y != -1 {
+		t.Errorf("State().Y = %d after moving up from the spawn row, want -1", state.Y)
+	}
+}
+
 func TestTetro_CanMoveDown(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -187,7 +211,7 @@ func TestTetro_CanMoveDown(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupTetro:       func() Tetro { return newO() },
+			setupTetro:       func() Tetro { return f.newO() },
 		},
 		{
 			name:             "cannot move down at bottom",
@@ -196,7 +220,7 @@ func TestTetro_CanMoveDown(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         false,
 			setupTetro: func() Tetro {
-				o := newO()
+				o := f.newO()
 				o.MoveDown()
 				return o
 			},
@@ -209,14 +233,14 @@ func TestTetro_CanMoveDown(t *testing.T) {
 				{x: 5, y: 2, style: "block"},
 			},
 			expected:   false,
-			setupTetro: func() Tetro { return newO() },
+			setupTetro: func() Tetro { return f.newO() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tetro := tt.setupTetro()
-			result := tetro.CanMoveDown(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := tetro.CanMoveDown(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanMoveDown() = %v, want %v", result, tt.expected)
 			}
@@ -225,7 +249,7 @@ func TestTetro_CanMoveDown(t *testing.T) {
 }
 
 func TestTetro_CanMoveRight(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -251,7 +275,7 @@ func TestTetro_CanMoveRight(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupTetro:       func() Tetro { return newO() },
+			setupTetro:       func() Tetro { return f.newO() },
 		},
 		{
 			name:             "cannot move right at right edge",
@@ -260,7 +284,7 @@ func TestTetro_CanMoveRight(t *testing.T) {
 			stationaryBlocks: []Block{},
 			expected:         false,
 			setupTetro: func() Tetro {
-				o := newO()
+				o := f.newO()
 				o.MoveRight()
 				return o
 			},
@@ -273,14 +297,14 @@ func TestTetro_CanMoveRight(t *testing.T) {
 				{x: 7, y: 0, style: "block"},
 			},
 			expected:   false,
-			setupTetro: func() Tetro { return newO() },
+			setupTetro: func() Tetro { return f.newO() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tetro := tt.setupTetro()
-			result := tetro.CanMoveRight(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := tetro.CanMoveRight(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanMoveRight() = %v, want %v", result, tt.expected)
 			}
@@ -289,7 +313,7 @@ func TestTetro_CanMoveRight(t *testing.T) {
 }
 
 func TestTetro_CanMoveLeft(t *testing.T) {
-	Init(Config{
+	f := NewFactory(Config{
 		SpawnX: 5,
 		SpawnY: 0,
 		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -315,7 +339,7 @@ func TestTetro_CanMoveLeft(t *testing.T) {
 			boardWidth:       10,
 			stationaryBlocks: []Block{},
 			expected:         true,
-			setupTetro:       func() Tetro { return newO() },
+			setupTetro:       func() Tetro { return f.newO() },
 		},
 		{
 			name:             "cannot move left at left edge",
@@ -325,7 +349,7 @@ func TestTetro_CanMoveLeft(t *testing.T) {
 			expected:         false,
 			setupTetro: func() Tetro {
 
-				Init(Config{
+				f := NewFactory(Config{
 					SpawnX: 0,
 					SpawnY: 0,
 					StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
@@ -336,7 +360,7 @@ func TestTetro_CanMoveLeft(t *testing.T) {
 					StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
 					StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
 				})
-				return newO()
+				return f.newO()
 			},
 		},
 		{
@@ -347,14 +371,14 @@ func TestTetro_CanMoveLeft(t *testing.T) {
 				{x: 4, y: 0, style: "block"},
 			},
 			expected:   false,
-			setupTetro: func() Tetro { return newO() },
+			setupTetro: func() Tetro { return f.newO() },
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tetro := tt.setupTetro()
-			result := tetro.CanMoveLeft(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks)
+			result := tetro.CanMoveLeft(newTestBoard(tt.boardHeight, tt.boardWidth, tt.stationaryBlocks))
 			if result != tt.expected {
 				t.Errorf("CanMoveLeft() = %v, want %v", result, tt.expected)
 			}
@@ -413,3 +437,120 @@ func TestClone(t *testing.T) {
 		}
 	}
 }
+
+func TestTetro_Spawn(t *testing.T) {
+	f := NewFactory(Config{
+		SpawnX: 5,
+		SpawnY: 0,
+		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3"},
+		StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3"},
+		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3"},
+		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3"},
+		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3"},
+		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3"},
+		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3"},
+	})
+
+	for _, factory := range tetroFactories {
+		original := factory(f)
+		expected := original.Blocks()
+
+		original.MoveDown()
+		original.MoveDown()
+		original.MoveLeft()
+		original.Rotate(NewBoard(20, 10))
+
+		spawned := original.Spawn()
+		if fmt.Sprintf("%T", spawned) != fmt.Sprintf("%T", original) {
+			t.Errorf("Spawn() returned %T, want %T", spawned, original)
+		}
+
+		for i, block := range spawned.Blocks() {
+			x, y := block.Coordinates()
+			expectedX, expectedY := expected[i].Coordinates()
+			if x != expectedX || y != expectedY {
+				t.Errorf("%T Spawn() block %d at (%d,%d), want (%d,%d)", original, i, x, y, expectedX, expectedY)
+			}
+		}
+	}
+}
+
+func TestTetro_Ghost(t *testing.T) {
+	f := NewFactory(Config{
+		SpawnX: 5,
+		SpawnY: 0,
+		StyleO: BlockStyles{Block0: "O0", Block1: "O1", Block2: "O2", Block3: "O3", Ghost: "OG"},
+		StyleI: BlockStyles{Block0: "I0", Block1: "I1", Block2: "I2", Block3: "I3", Ghost: "IG"},
+		StyleS: BlockStyles{Block0: "S0", Block1: "S1", Block2: "S2", Block3: "S3", Ghost: "SG"},
+		StyleZ: BlockStyles{Block0: "Z0", Block1: "Z1", Block2: "Z2", Block3: "Z3", Ghost: "ZG"},
+		StyleL: BlockStyles{Block0: "L0", Block1: "L1", Block2: "L2", Block3: "L3", Ghost: "LG"},
+		StyleJ: BlockStyles{Block0: "J0", Block1: "J1", Block2: "J2", Block3: "J3", Ghost: "JG"},
+		StyleT: BlockStyles{Block0: "T0", Block1: "T1", Block2: "T2", Block3: "T3", Ghost: "TG"},
+	})
+
+	tests := []struct {
+		name             string
+		stationaryBlocks []Block
+		expectedCoords   []struct{ x, y int }
+	}{
+		{
+			name:             "ghost on the floor of an empty board",
+			stationaryBlocks: []Block{},
+			expectedCoords:   []struct{ x, y int }{{5, 18}, {5, 19}, {6, 19}, {6, 18}},
+		},
+		{
+			name: "ghost on top of stationary blocks",
+			stationaryBlocks: []Block{
+				{x: 6, y: 12, style: "block"},
+				{x: 5, y: 15, style: "block"},
+			},
+			expectedCoords: []struct{ x, y int }{{5, 10}, {5, 11}, {6, 11}, {6, 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := f.newO()
+			ghost := o.Ghost(newTestBoard(20, 10, tt.stationaryBlocks))
+
+			for i, block := range ghost {
+				x, y := block.Coordinates()
+				if x != tt.expectedCoords[i].x || y != tt.expectedCoords[i].y {
+					t.Errorf("Ghost block %d at (%d,%d), want (%d,%d)", i, x, y, tt.expectedCoords[i].x, tt.expectedCoords[i].y)
+				}
+				if block.Style() != "OG" {
+					t.Errorf("Ghost block %d style = %v, want %v", i, block.Style(), "OG")
+				}
+			}
+
+			// the tetro itself doesn't move
+			x, y := o.Blocks()[0].Coordinates()
+			if x != 5 || y != 0 {
+				t.Errorf("Ghost() moved the tetro to (%d,%d)", x, y)
+			}
+		})
+	}
+}
+
+func TestShape(t *testing.T) {
+	for shape := ShapeO; shape <= ShapeT; shape++ {
+		text, err := shape.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v) unexpected error: %v", shape, err)
+		}
+		var parsed Shape
+		if err := parsed.UnmarshalText(text); err != nil || parsed != shape {
+			t.Errorf("UnmarshalText(%q) = %v, %v, want %v", text, parsed, err, shape)
+		}
+	}
+
+	if name := ShapeT.String(); name != "T" {
+		t.Errorf("String() = %q, want %q", name, "T")
+	}
+	if _, err := Shape(7).MarshalText(); err == nil {
+		t.Error("MarshalText(7) expected error, got nil")
+	}
+	if _, err := ParseShape("X"); err == nil {
+		t.Error("ParseShape(\"X\") expected error, got nil")
+	}
+}
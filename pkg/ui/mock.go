@@ -9,6 +9,10 @@ type MockUI struct {
 	blockStyles  tetris.BlockStyles
 	Started      bool
 	Stopped      bool
+	HighScores   *HighScoreTable // table last shown, nil if hidden
+	Menu         *Menu           // menu last shown, nil if hidden
+	Mode         ModeStatus
+	Blocks       []tetris.Block // blocks last drawn on the board, ghost included
 }
 
 func newMockUI() (UI, func()) {
@@ -19,6 +23,7 @@ func newMockUI() (UI, func()) {
 			Block1: "style1",
 			Block2: "style2",
 			Block3: "style3",
+			Ghost:  "ghost",
 		},
 	}, func() {}
 }
@@ -33,7 +38,24 @@ func (m *MockUI) GetBlockStyles() (o, i, s, z, l, j, t tetris.BlockStyles) {
 	return m.blockStyles, m.blockStyles, m.blockStyles, m.blockStyles, m.blockStyles, m.blockStyles, m.blockStyles
 }
 
-func (m *MockUI) Update(blocks []tetris.Block, queue []tetris.Tetro, score, level, linesCleared int, status Status) {
+func (m *MockUI) GetGarbageStyle() any {
+	return "garbage"
+}
+
+func (m *MockUI) Update(blocks, ghost []tetris.Block, queue []tetris.Tetro, held tetris.Tetro, score, level, linesCleared int, action string, status Status) {
+	m.Blocks = append(append([]tetris.Block(nil), ghost...), blocks...)
+}
+
+func (m *MockUI) ShowHighScores(table *HighScoreTable) {
+	m.HighScores = table
+}
+
+func (m *MockUI) ShowMode(mode ModeStatus) {
+	m.Mode = mode
+}
+
+func (m *MockUI) ShowMenu(menu *Menu) {
+	m.Menu = menu
 }
 
 func (m *MockUI) KeyPress() <-chan KeyPress {
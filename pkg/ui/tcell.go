@@ -4,24 +4,29 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 
 	"github.com/garyloug/tetris/pkg/tetris"
 	tcellLib "github.com/gdamore/tcell/v2"
 )
 
 const (
-	full        = '█'
-	shade       = '▓'
-	oColour     = tcellLib.ColorYellow
-	iColour     = tcellLib.ColorMediumTurquoise
-	sColour     = tcellLib.ColorLimeGreen
-	zColour     = tcellLib.ColorRed
-	lColour     = tcellLib.ColorOrange
-	jColour     = tcellLib.ColorDarkBlue
-	tColour     = tcellLib.ColorRebeccaPurple
-	bgColour    = tcellLib.ColorDimGrey
-	xMultiplier = 2 // block is 2x1 characters wide
-	yMultiplier = 1 // block is 1 character tall
+	full          = '█'
+	shade         = '▓'
+	light         = '░'
+	oColour       = tcellLib.ColorYellow
+	iColour       = tcellLib.ColorMediumTurquoise
+	sColour       = tcellLib.ColorLimeGreen
+	zColour       = tcellLib.ColorRed
+	lColour       = tcellLib.ColorOrange
+	jColour       = tcellLib.ColorDarkBlue
+	tColour       = tcellLib.ColorRebeccaPurple
+	bgColour      = tcellLib.ColorDimGrey
+	garbageColour = tcellLib.ColorSilver
+	xMultiplier   = 2 // block is 2x1 characters wide
+	yMultiplier   = 1 // block is 1 character tall
+	panelLeft     = 2 // screen position of the text in panels drawn over the game
+	panelTop      = 2
 )
 
 var (
@@ -54,6 +59,21 @@ var (
 		style: tcellLib.StyleDefault.Background(tColour).Foreground(tColour),
 		fill:  full,
 	}
+
+	// ghost styles are a dimmed version of each tetro colour over the board background
+	oGhostStyle = ghostStyle(oColour)
+	iGhostStyle = ghostStyle(iColour)
+	sGhostStyle = ghostStyle(sColour)
+	zGhostStyle = ghostStyle(zColour)
+	lGhostStyle = ghostStyle(lColour)
+	jGhostStyle = ghostStyle(jColour)
+	tGhostStyle = ghostStyle(tColour)
+
+	// garbage is a solid grey, unlike any tetro
+	garbageStyle = TcellStyle{
+		style: tcellLib.StyleDefault.Background(garbageColour).Foreground(garbageColour),
+		fill:  full,
+	}
 )
 
 type TcellStyle struct {
@@ -61,12 +81,22 @@ type TcellStyle struct {
 	fill  rune
 }
 
+func ghostStyle(colour tcellLib.Color) TcellStyle {
+	return TcellStyle{
+		style: tcellLib.StyleDefault.Background(bgColour).Foreground(colour).Dim(true),
+		fill:  light,
+	}
+}
+
 type tcell struct {
 	ui
 	screen     tcellLib.Screen
 	boardStyle tcellLib.Style
 	quit       chan struct{}
 	bgFill     rune
+	highScores *HighScoreTable
+	menu       *Menu
+	mode       ModeStatus
 }
 
 func newTcellUI() (UI, func()) {
@@ -102,13 +132,15 @@ func newTcellUI() (UI, func()) {
 
 	tc.ui = ui{
 		eventChan: make(chan KeyPress, 10),
-		oStyles:   tetris.BlockStyles{Block0: oStyle, Block1: oStyle, Block2: oStyle, Block3: oStyle},
-		iStyles:   tetris.BlockStyles{Block0: iStyle, Block1: iStyle, Block2: iStyle, Block3: iStyle},
-		sStyles:   tetris.BlockStyles{Block0: sStyle, Block1: sStyle, Block2: sStyle, Block3: sStyle},
-		zStyles:   tetris.BlockStyles{Block0: zStyle, Block1: zStyle, Block2: zStyle, Block3: zStyle},
-		lStyles:   tetris.BlockStyles{Block0: lStyle, Block1: lStyle, Block2: lStyle, Block3: lStyle},
-		jStyles:   tetris.BlockStyles{Block0: jStyle, Block1: jStyle, Block2: jStyle, Block3: jStyle},
-		tStyles:   tetris.BlockStyles{Block0: tStyle, Block1: tStyle, Block2: tStyle, Block3: tStyle},
+		oStyles:   tetris.BlockStyles{Block0: oStyle, Block1: oStyle, Block2: oStyle, Block3: oStyle, Ghost: oGhostStyle},
+		iStyles:   tetris.BlockStyles{Block0: iStyle, Block1: iStyle, Block2: iStyle, Block3: iStyle, Ghost: iGhostStyle},
+		sStyles:   tetris.BlockStyles{Block0: sStyle, Block1: sStyle, Block2: sStyle, Block3: sStyle, Ghost: sGhostStyle},
+		zStyles:   tetris.BlockStyles{Block0: zStyle, Block1: zStyle, Block2: zStyle, Block3: zStyle, Ghost: zGhostStyle},
+		lStyles:   tetris.BlockStyles{Block0: lStyle, Block1: lStyle, Block2: lStyle, Block3: lStyle, Ghost: lGhostStyle},
+		jStyles:   tetris.BlockStyles{Block0: jStyle, Block1: jStyle, Block2: jStyle, Block3: jStyle, Ghost: jGhostStyle},
+		tStyles:   tetris.BlockStyles{Block0: tStyle, Block1: tStyle, Block2: tStyle, Block3: tStyle, Ghost: tGhostStyle},
+
+		garbageStyle: garbageStyle,
 	}
 
 	tc.screen.Show()
@@ -137,7 +169,11 @@ func (tc *tcell) GetBlockStyles() (o, i, s, z, l, j, t tetris.BlockStyles) {
 	return tc.oStyles, tc.iStyles, tc.sStyles, tc.zStyles, tc.lStyles, tc.jStyles, tc.tStyles
 }
 
-func (tc *tcell) Update(blocks []tetris.Block, queue []tetris.Tetro, score, level, linesCleared int, status Status) {
+func (tc *tcell) GetGarbageStyle() any {
+	return tc.garbageStyle
+}
+
+func (tc *tcell) Update(blocks, ghost []tetris.Block, queue []tetris.Tetro, held tetris.Tetro, score, level, linesCleared int, action string, status Status) {
 	tc.screen.Clear()
 
 	// draw the board
@@ -148,6 +184,15 @@ func (tc *tcell) Update(blocks []tetris.Block, queue []tetris.Tetro, score, leve
 		}
 	}
 
+	// draw the ghost first, so the active tetro is drawn over it where they overlap
+	for _, block := range ghost {
+		x, y := block.Coordinates()
+		style := block.Style().(TcellStyle)
+
+		tc.screen.SetContent(x*xMultiplier, y*yMultiplier, style.fill, nil, style.style)
+		tc.screen.SetContent(x*xMultiplier+1, y*yMultiplier, style.fill, nil, style.style)
+	}
+
 	// draw the blocks
 	for _, block := range blocks {
 		x, y := block.Coordinates()
@@ -159,45 +204,13 @@ func (tc *tcell) Update(blocks []tetris.Block, queue []tetris.Tetro, score, leve
 		tc.screen.SetContent(x*xMultiplier+1, y*yMultiplier, fill, nil, tcellStyle)
 	}
 
-	// draw the tetro queue
-	xOffset := tc.boardWidth + 5
-	yOffset := 1
+	// draw the tetro queue beside the board, whichever columns the tetros spawn in, then the text beside the queue
+	queueX := tc.boardWidth*xMultiplier + 2
+	sideX := queueX + 4*xMultiplier + 5 // the widest tetro is 4 blocks
+	queueY := 1
 	for _, tetro := range queue {
-		minY, maxY := 0, 0
-		first := true
-
-		// find the height of the tetro
-		for _, block := range tetro.Blocks() {
-			_, y := block.Coordinates()
-			if first {
-				minY, maxY = y, y
-				first = false
-			} else {
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
-			}
-		}
-
-		// draw the tetro, adjust Y to allow for height
-		yAdjustment := yOffset - minY
-		for _, block := range tetro.Blocks() {
-			x, y := block.Coordinates()
-			style := block.Style().(TcellStyle)
-			fill := style.fill
-			tcellStyle := style.style
-
-			adjustedY := y + yAdjustment
-			tc.screen.SetContent(xOffset+x*xMultiplier, adjustedY*yMultiplier, fill, nil, tcellStyle)
-			tc.screen.SetContent(xOffset+x*xMultiplier+1, adjustedY*yMultiplier, fill, nil, tcellStyle)
-		}
-
-		// move yOffset by the actual height of this tetro + 1 for gap
-		tetroHeight := maxY - minY + 1
-		yOffset += tetroHeight + 1
+		tc.drawTetro(tetro, queueX, queueY)
+		queueY += tetroHeight(tetro) + 1
 	}
 
 	// draw the score, level and lines cleared
@@ -205,32 +218,31 @@ func (tc *tcell) Update(blocks []tetris.Block, queue []tetris.Tetro, score, leve
 
 	scoreText := "Score: " + strconv.Itoa(score)
 	for i, char := range scoreText {
-		tc.screen.SetContent(tc.boardWidth*xMultiplier+15+i, 0, char, nil, style)
+		tc.screen.SetContent(sideX+i, 0, char, nil, style)
 	}
 
 	levelText := "Level: " + strconv.Itoa(level)
 	for i, char := range levelText {
-		tc.screen.SetContent(tc.boardWidth*xMultiplier+15+i, 1, char, nil, style)
+		tc.screen.SetContent(sideX+i, 1, char, nil, style)
 	}
 
 	clearedText := "Lines: " + strconv.Itoa(linesCleared)
 	for i, char := range clearedText {
-		tc.screen.SetContent(tc.boardWidth*xMultiplier+15+i, 2, char, nil, style)
+		tc.screen.SetContent(sideX+i, 2, char, nil, style)
 	}
 
-	// draw instructions
-	instructions := []string{
-		"← - Left",
-		"→ - Right",
-		"↓ - Down",
-		"↑ - Rotate",
-		"⎵ - Pause",
-		"Esc - Quit",
+	// draw the last scoring action
+	for i, char := range action {
+		tc.screen.SetContent(sideX+i, 3, char, nil, style)
 	}
-	for i, line := range instructions {
-		for j, char := range line {
-			tc.screen.SetContent(tc.boardWidth*xMultiplier+15+j, 5+i, char, nil, style)
-		}
+
+	// draw the held tetro
+	holdText := "Hold:"
+	for i, char := range holdText {
+		tc.screen.SetContent(sideX+i, 4, char, nil, style)
+	}
+	if held != nil {
+		tc.drawTetro(held, sideX, 5)
 	}
 
 	// draw status
@@ -244,13 +256,230 @@ func (tc *tcell) Update(blocks []tetris.Block, queue []tetris.Tetro, score, leve
 		statusText = "Status: Game Over"
 	}
 	for i, char := range statusText {
-		tc.screen.SetContent(tc.boardWidth*xMultiplier+15+i, 12, char, nil, style)
+		tc.screen.SetContent(sideX+i, 8, char, nil, style)
+	}
+
+	// draw the mode, its progress and timer
+	modeText := tc.mode.Name
+	if tc.mode.Goal != "" {
+		modeText += ": " + tc.mode.Goal
+	}
+	for i, char := range modeText {
+		tc.screen.SetContent(sideX+i, 9, char, nil, style)
+	}
+	timerText := "Time: " + FormatDuration(tc.mode.Timer)
+	for i, char := range timerText {
+		tc.screen.SetContent(sideX+i, 10, char, nil, style)
+	}
+
+	// draw instructions
+	instructions := []string{
+		"←→ - Move",
+		"↓ - Soft Drop",
+		"⎵ - Hard Drop",
+		"↑ - Rotate",
+		"Z - Rotate Left",
+		"A - Rotate 180",
+		"C - Hold",
+		"G - Ghost",
+		"P - Pause",
+		"R - Restart (paused/over)",
+		"Esc - Quit",
+	}
+	for i, line := range instructions {
+		for j, char := range line {
+			tc.screen.SetContent(sideX+j, 12+i, char, nil, style)
+		}
+	}
+
+	if tc.highScores != nil {
+		tc.drawHighScores(tc.highScores)
+	} else if status == GameOver {
+		results := tc.mode.Results
+		if len(results) == 0 {
+			results = []string{"Game Over"}
+		}
+		help := tc.mode.Help
+		if help == "" {
+			help = "R - Restart  Esc - Quit"
+		}
+		tc.drawPanel(append(append([]string(nil), results...), "", help))
+	}
+	if tc.menu != nil {
+		if tc.menu.Cover {
+			tc.screen.Clear()
+		}
+		tc.drawMenu(tc.menu)
 	}
 
 	// show the screen
 	tc.screen.Show()
 }
 
+func (tc *tcell) ShowHighScores(table *HighScoreTable) {
+	tc.highScores = table
+}
+
+// drawHighScores draws the high score table in a panel over the top left of the screen
+func (tc *tcell) drawHighScores(table *HighScoreTable) {
+	entryStyle := tcellLib.StyleDefault.Background(tcellLib.ColorBlack).Foreground(tcellLib.ColorYellow)
+
+	var lines []string
+	if len(table.Text) > 0 {
+		lines = append(append(lines, table.Text...), "")
+	}
+	header := len(lines) + 2 // rows of the table follow the title, a gap and the header
+	lines = append(lines,
+		table.Title,
+		"",
+		fmt.Sprintf("    %-3s %9s %5s %5s %9s %10s %s", "", "Score", "Level", "Lines", "Time", "Date", "Seed"))
+	for i, row := range table.Rows {
+		lines = append(lines, fmt.Sprintf("%2d. %-3s %9d %5d %5d %9s %10s %d",
+			i+1, row.Name, row.Score, row.Level, row.Lines, FormatDuration(row.Duration), row.Date.Format("2006-01-02"), row.Seed))
+	}
+	if len(table.Rows) == 0 {
+		lines = append(lines, "    No high scores yet")
+	}
+	lines = append(lines, "")
+	if table.Naming {
+		lines = append(lines, "New high score! ↑↓ - Letter  ←→ - Move  ⏎ - Save")
+	} else if table.Help != "" {
+		lines = append(lines, table.Help)
+	} else {
+		lines = append(lines, "R - Restart  Esc - Quit")
+	}
+
+	tc.drawPanel(lines)
+
+	// the entry row is highlighted, and the letter being entered is shown in reverse after the 4 character rank
+	if table.Entry < 0 || table.Entry >= len(table.Rows) {
+		return
+	}
+	y := panelTop + header + 1 + table.Entry
+	for x, char := range []rune(lines[header+1+table.Entry]) {
+		charStyle := entryStyle
+		if table.Naming && x == 4+table.Cursor {
+			charStyle = charStyle.Reverse(true)
+		}
+		tc.screen.SetContent(panelLeft+x, y, char, nil, charStyle)
+	}
+}
+
+func (tc *tcell) ShowMode(mode ModeStatus) {
+	tc.mode = mode
+}
+
+func (tc *tcell) ShowMenu(menu *Menu) {
+	tc.menu = menu
+}
+
+// drawMenu draws a menu in a panel, with the selected item highlighted and its setting between arrows
+func (tc *tcell) drawMenu(menu *Menu) {
+	selectedStyle := tcellLib.StyleDefault.Background(tcellLib.ColorBlack).Foreground(tcellLib.ColorYellow)
+
+	labelWidth := 0
+	for _, item := range menu.Items {
+		if n := len([]rune(item.Label)); n > labelWidth {
+			labelWidth = n
+		}
+	}
+
+	lines := []string{menu.Title, ""}
+	if len(menu.Text) > 0 {
+		lines = append(append(lines, menu.Text...), "")
+	}
+	first := len(lines)
+	for i, item := range menu.Items {
+		line := "  " + item.Label
+		if i == menu.Selected {
+			line = "> " + item.Label
+		}
+		if item.Value != "" {
+			padding := strings.Repeat(" ", labelWidth-len([]rune(item.Label)))
+			if i == menu.Selected {
+				line += padding + "  < " + item.Value + " >"
+			} else {
+				line += padding + "    " + item.Value
+			}
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "↑↓ - Select  ←→ - Change  ⏎ - OK  Esc - Back")
+
+	tc.drawPanel(lines)
+
+	if menu.Selected < 0 || menu.Selected >= len(menu.Items) {
+		return
+	}
+	for x, char := range []rune(lines[first+menu.Selected]) {
+		tc.screen.SetContent(panelLeft+x, panelTop+first+menu.Selected, char, nil, selectedStyle)
+	}
+}
+
+// drawPanel draws lines of text on a plain background over the top left of the screen
+func (tc *tcell) drawPanel(lines []string) {
+	style := tcellLib.StyleDefault.Background(tcellLib.ColorBlack).Foreground(tcellLib.ColorWhite)
+
+	width := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > width {
+			width = n
+		}
+	}
+
+	// a border of one row and two columns of background around the text
+	for y := panelTop - 1; y <= panelTop+len(lines); y++ {
+		for x := panelLeft - 2; x < panelLeft+width+2; x++ {
+			tc.screen.SetContent(x, y, ' ', nil, style)
+		}
+	}
+	for y, line := range lines {
+		for x, char := range []rune(line) {
+			tc.screen.SetContent(panelLeft+x, panelTop+y, char, nil, style)
+		}
+	}
+}
+
+// drawTetro draws a tetro with its top left corner at the given screen position, wherever it is on the board
+func (tc *tcell) drawTetro(tetro tetris.Tetro, screenX, screenY int) {
+	blocks := tetro.Blocks()
+	minX, minY := blocks[0].Coordinates()
+	for _, block := range blocks[1:] {
+		x, y := block.Coordinates()
+		if x < minX {
+			minX = x
+		}
+		if y < minY {
+			minY = y
+		}
+	}
+
+	for _, block := range blocks {
+		x, y := block.Coordinates()
+		style := block.Style().(TcellStyle)
+
+		tc.screen.SetContent(screenX+(x-minX)*xMultiplier, screenY+(y-minY)*yMultiplier, style.fill, nil, style.style)
+		tc.screen.SetContent(screenX+(x-minX)*xMultiplier+1, screenY+(y-minY)*yMultiplier, style.fill, nil, style.style)
+	}
+}
+
+// tetroHeight is the number of rows the tetro takes up
+func tetroHeight(tetro tetris.Tetro) int {
+	blocks := tetro.Blocks()
+	_, minY := blocks[0].Coordinates()
+	maxY := minY
+	for _, block := range blocks[1:] {
+		_, y := block.Coordinates()
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
+	return maxY - minY + 1
+}
+
 func (tc *tcell) Start() {
 	go tc.run()
 }
@@ -293,8 +522,22 @@ func (tc *tcell) run() {
 				tc.ui.eventChan <- KeyDown
 			} else if ev.Key() == tcellLib.KeyUp {
 				tc.ui.eventChan <- KeyUp
+			} else if ev.Rune() == 'z' || ev.Rune() == 'Z' {
+				tc.ui.eventChan <- KeyRotateLeft
+			} else if ev.Rune() == 'a' || ev.Rune() == 'A' {
+				tc.ui.eventChan <- KeyRotate180
+			} else if ev.Rune() == 'c' || ev.Rune() == 'C' {
+				tc.ui.eventChan <- KeyHold
 			} else if ev.Rune() == ' ' {
+				tc.ui.eventChan <- KeyHardDrop
+			} else if ev.Rune() == 'g' || ev.Rune() == 'G' {
+				tc.ui.eventChan <- KeyGhost
+			} else if ev.Rune() == 'p' || ev.Rune() == 'P' {
 				tc.ui.eventChan <- KeyPause
+			} else if ev.Rune() == 'r' || ev.Rune() == 'R' {
+				tc.ui.eventChan <- KeyRestart
+			} else if ev.Key() == tcellLib.KeyEnter {
+				tc.ui.eventChan <- KeyEnter
 			}
 		}
 	}
@@ -320,4 +563,8 @@ func (tc *tcell) setDevStyles() {
 			fill:  '3',
 		}
 	}
+	tc.garbageStyle = TcellStyle{
+		style: garbageStyle.style,
+		fill:  'G',
+	}
 }
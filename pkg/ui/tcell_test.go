@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/garyloug/tetris/pkg/tetris"
+	tcellLib "github.com/gdamore/tcell/v2"
 )
 
 func TestTcell_Init(t *testing.T) {
@@ -182,4 +185,143 @@ func TestTcell_SetDevStyles(t *testing.T) {
 	checkDevStyle(tc.lStyles, "L")
 	checkDevStyle(tc.jStyles, "J")
 	checkDevStyle(tc.tStyles, "T")
+	if fill := tc.GetGarbageStyle().(TcellStyle).fill; fill != 'G' {
+		t.Errorf("setDevStyles() garbage fill = %c, want 'G'", fill)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		want     string
+	}{
+		{0, "0:00.000"},
+		{1500 * time.Millisecond, "0:01.500"},
+		{62*time.Second + 345*time.Millisecond, "1:02.345"},
+		{time.Hour, "60:00.000"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.duration); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestTcell_DrawHighScores(t *testing.T) {
+	screen := tcellLib.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 25)
+
+	tc := &tcell{screen: screen}
+	tc.drawHighScores(&HighScoreTable{
+		Title:  "High Scores",
+		Rows:   []HighScoreRow{{Name: "ABC", Score: 1234, Level: 2, Lines: 25, Duration: time.Minute}},
+		Entry:  0,
+		Naming: true,
+		Cursor: 1,
+	})
+
+	// the first row is under the title, a gap and the header, and the name follows the rank
+	row := ""
+	for x := 0; x < 20; x++ {
+		char, _, _, _ := screen.GetContent(x, 5)
+		row += string(char)
+	}
+	if !strings.Contains(row, "1. ABC") {
+		t.Errorf("first row = %q, want it to show the entry", row)
+	}
+
+	_, _, style, _ := screen.GetContent(strings.Index(row, "B"), 5)
+	if _, _, attrs := style.Decompose(); attrs&tcellLib.AttrReverse == 0 {
+		t.Error("letter under the cursor isn't highlighted")
+	}
+}
+
+func TestTcell_DrawMenu(t *testing.T) {
+	screen := tcellLib.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(80, 25)
+
+	tc := &tcell{screen: screen}
+	tc.drawMenu(&Menu{
+		Title:    "Options",
+		Items:    []MenuItem{{Label: "Generator", Value: "7bag"}, {Label: "Lock", Value: "move"}, {Label: "Back"}},
+		Selected: 0,
+	})
+
+	line := func(y int) string {
+		text := ""
+		for x := 0; x < 40; x++ {
+			char, _, _, _ := screen.GetContent(x, y)
+			text += string(char)
+		}
+		return strings.TrimSpace(text)
+	}
+
+	// the items follow the title and a gap, the selected one shows its setting between arrows
+	tests := []struct {
+		y    int
+		want string
+	}{
+		{panelTop, "Options"},
+		{panelTop + 2, "> Generator  < 7bag >"},
+		{panelTop + 3, "Lock         move"}, // settings line up with the selected one,
+		{panelTop + 4, "Back"},
+	}
+	for _, tt := range tests {
+		if got := line(tt.y); got != tt.want {
+			t.Errorf("line %d = %q, want %q", tt.y, got, tt.want)
+		}
+	}
+
+	_, _, style, _ := screen.GetContent(panelLeft, panelTop+2)
+	if fg, _, _ := style.Decompose(); fg != tcellLib.ColorYellow {
+		t.Errorf("selected item colour = %v, want yellow", fg)
+	}
+}
+
+func TestTcell_UpdateWideBoard(t *testing.T) {
+	screen := tcellLib.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(100, 40)
+
+	tc := &tcell{screen: screen}
+	if err := tc.Init(20, 30); err != nil {
+		t.Fatalf("Init() unexpected error: %v", err)
+	}
+
+	// the queued tetro spawns far to the right on the wide board, but is drawn just beside it
+	style := TcellStyle{fill: 'I'}
+	factory := tetris.NewFactory(tetris.Config{SpawnX: 15, StyleI: tetris.BlockStyles{Block0: style, Block1: style, Block2: style, Block3: style}})
+	queued, err := factory.NewTetro(tetris.ShapeI)
+	if err != nil {
+		t.Fatalf("failed to create tetro: %v", err)
+	}
+	tc.Update(nil, nil, []tetris.Tetro{queued}, nil, 0, 0, 0, "", Running)
+
+	queueX := 30*xMultiplier + 2
+	if char, _, _, _ := screen.GetContent(queueX, 1); char != 'I' {
+		t.Errorf("queue at (%d,1) = %q, want 'I'", queueX, char)
+	}
+
+	// the score follows the queue, clear of the widest tetro
+	sideX := queueX + 4*xMultiplier + 5
+	text := ""
+	for x := sideX; x < sideX+8; x++ {
+		char, _, _, _ := screen.GetContent(x, 0)
+		text += string(char)
+	}
+	if text != "Score: 0" {
+		t.Errorf("score = %q, want %q", text, "Score: 0")
+	}
 }
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/garyloug/tetris/pkg/tetris"
 )
@@ -19,6 +20,13 @@ const (
 	Console UiType = iota
 	ConsoleDev
 	Mock
+	KeyRotateLeft KeyPress = iota
+	KeyRotate180
+	KeyHold
+	KeyHardDrop
+	KeyGhost
+	KeyEnter
+	KeyRestart
 )
 
 type (
@@ -30,7 +38,18 @@ type (
 type UI interface {
 	Init(boardHeight, boardWidth int) error
 	GetBlockStyles() (o, i, s, z, l, j, t tetris.BlockStyles)
-	Update(blocks []tetris.Block, queue []tetris.Tetro, score, level, linesCleared int, status Status)
+	GetGarbageStyle() any
+	// ghost is nil when the ghost is turned off, held is nil until the first tetro is held,
+	// action describes the last scoring action, e.g. "T-Spin Double", and is empty when there's nothing to show
+	Update(blocks, ghost []tetris.Block, queue []tetris.Tetro, held tetris.Tetro, score, level, linesCleared int, action string, status Status)
+
+	// ShowHighScores shows a high score table over the game on every update from now on, nil hides it again
+	ShowHighScores(table *HighScoreTable)
+	// ShowMode shows the game's mode, timer and progress beside the board on every update from now on,
+	// and the mode's results once the game is over
+	ShowMode(mode ModeStatus)
+	// ShowMenu shows a menu over the game and any high score table on every update from now on, nil hides it again
+	ShowMenu(menu *Menu)
 
 	KeyPress() <-chan KeyPress
 
@@ -38,6 +57,59 @@ type UI interface {
 	Stop()
 }
 
+// HighScoreTable is a table of the best games, shown once a game is over
+type HighScoreTable struct {
+	Title  string
+	Rows   []HighScoreRow
+	Entry  int      // row of the game just played, -1 if it didn't make the table
+	Naming bool     // the player is entering their name on the entry row
+	Cursor int      // letter of the name being entered
+	Help   string   // keys shown under the table, restart and quit if empty
+	Text   []string // lines shown above the table, e.g. the results of the game just played
+}
+
+type HighScoreRow struct {
+	Name     string
+	Score    int
+	Level    int
+	Lines    int
+	Duration time.Duration
+	Seed     int64
+	Date     time.Time
+}
+
+// ModeStatus is what the game's mode shows beside the board, and its results once the game is over
+type ModeStatus struct {
+	Name    string        // e.g. "Sprint"
+	Timer   time.Duration // time played, or time left in a timed mode
+	Goal    string        // progress towards the mode's goal, e.g. "12/40 lines", empty if it has none
+	Results []string      // shown once the game is over, e.g. the time taken and pieces per second
+	Help    string        // keys shown under the results once the game is over
+}
+
+// FormatDuration shows a duration as minutes, seconds and milliseconds, e.g. 1:02.345
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	minutes := int(d / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+	millis := int(d % time.Second / time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%03d", minutes, seconds, millis)
+}
+
+// Menu is a list of items to choose from, moving up and down to select an item
+type Menu struct {
+	Title    string
+	Text     []string // lines shown above the items
+	Items    []MenuItem
+	Selected int
+	Cover    bool // hides the game behind the menu, e.g. for the title screen
+}
+
+type MenuItem struct {
+	Label string
+	Value string // setting changed with left and right, empty for items chosen with enter
+}
+
 func NewUI(uiType UiType) (UI, func(), error) {
 	var ui UI
 	var cleanup func()
@@ -70,6 +142,9 @@ type ui struct {
 	jStyles tetris.BlockStyles
 	tStyles tetris.BlockStyles
 
+	// block style for garbage rows
+	garbageStyle any
+
 	// event channel for user input
 	eventChan chan KeyPress
 }
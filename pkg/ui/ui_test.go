@@ -93,3 +93,13 @@ func TestNewUI(t *testing.T) {
 		})
 	}
 }
+
+func TestUiType_Values(t *testing.T) {
+	// keys added since the first release come after the UI types, so the UI types keep their values
+	if Console != 9 || ConsoleDev != 10 || Mock != 11 {
+		t.Errorf("UI types = %d, %d, %d, want 9, 10, 11", Console, ConsoleDev, Mock)
+	}
+	if KeyStop != 8 || KeyRotateLeft != 12 {
+		t.Errorf("KeyStop = %d, KeyRotateLeft = %d, want 8 and 12", KeyStop, KeyRotateLeft)
+	}
+}